@@ -0,0 +1,203 @@
+package main
+
+import "time"
+
+// ArbDirection identifies which side of a YES/NO pair's invariant is
+// being violated.
+type ArbDirection string
+
+const (
+	// ArbBuyBoth means buying one YES and one NO share costs less than
+	// $1 guaranteed payout: bestAsk(YES) + bestAsk(NO) < 1 - fees.
+	ArbBuyBoth ArbDirection = "buy_both"
+	// ArbSellBoth means selling one YES and one NO share pays out more
+	// than the $1 they're jointly worth: bestBid(YES) + bestBid(NO) > 1 + fees.
+	ArbSellBoth ArbDirection = "sell_both"
+)
+
+// ArbOpportunity is emitted when a YES/NO pair's combined best prices
+// break the $1 invariant by more than the configured threshold.
+type ArbOpportunity struct {
+	Timestamp   time.Time
+	ConditionID string
+	Direction   ArbDirection
+	Size        float64 // max executable size in shares, walking both books
+	EdgeBps     float64 // edge in basis points of the $1 payout
+}
+
+// ArbDetectorConfig tunes how aggressively the detector reports
+// opportunities.
+type ArbDetectorConfig struct {
+	FeesBps    float64 // round-trip fee budget to subtract from the $1 invariant, in bps
+	MinEdgeBps float64 // opportunities at or below this edge are not reported
+}
+
+// ArbDetector evaluates a BookGroup's YES/NO invariant on every update
+// and reports opportunities on Opportunities (and, if bus is set, on the
+// shared EventBus as EventArbOpportunity).
+type ArbDetector struct {
+	cfg           ArbDetectorConfig
+	bus           *EventBus
+	Opportunities chan ArbOpportunity
+}
+
+// NewArbDetector builds an ArbDetector. The caller must drain
+// Opportunities or Evaluate will block. bus may be nil, in which case
+// opportunities are only delivered on Opportunities.
+func NewArbDetector(cfg ArbDetectorConfig, bus *EventBus) *ArbDetector {
+	return &ArbDetector{
+		cfg:           cfg,
+		bus:           bus,
+		Opportunities: make(chan ArbOpportunity, 64),
+	}
+}
+
+// Evaluate checks group's current best bid/ask against the $1 invariant
+// and publishes an ArbOpportunity if the edge exceeds MinEdgeBps.
+func (d *ArbDetector) Evaluate(group *BookGroup, at time.Time) {
+	fees := d.cfg.FeesBps / 10000
+
+	if opp, ok := d.evaluateBuyBoth(group, fees, at); ok {
+		d.Opportunities <- opp
+		d.publish(opp)
+	}
+	if opp, ok := d.evaluateSellBoth(group, fees, at); ok {
+		d.Opportunities <- opp
+		d.publish(opp)
+	}
+}
+
+func (d *ArbDetector) publish(opp ArbOpportunity) {
+	if d.bus == nil {
+		return
+	}
+	d.bus.Publish(Event{
+		Type:      EventArbOpportunity,
+		AssetID:   opp.ConditionID,
+		Timestamp: opp.Timestamp,
+		Payload:   opp,
+	})
+}
+
+func (d *ArbDetector) evaluateBuyBoth(group *BookGroup, fees float64, at time.Time) (ArbOpportunity, bool) {
+	yesAsk, okY := group.Yes.BestAsk()
+	noAsk, okN := group.No.BestAsk()
+	if !okY || !okN {
+		return ArbOpportunity{}, false
+	}
+
+	cost := yesAsk.Price + noAsk.Price
+	edge := (1 - fees) - cost
+	edgeBps := edge * 10000
+	if edgeBps <= d.cfg.MinEdgeBps {
+		return ArbOpportunity{}, false
+	}
+
+	size := maxExecutableSize(group.Yes.View().Asks, group.No.View().Asks, 1-fees, true)
+	return ArbOpportunity{
+		Timestamp:   at,
+		ConditionID: group.ConditionID,
+		Direction:   ArbBuyBoth,
+		Size:        size,
+		EdgeBps:     edgeBps,
+	}, true
+}
+
+func (d *ArbDetector) evaluateSellBoth(group *BookGroup, fees float64, at time.Time) (ArbOpportunity, bool) {
+	yesBid, okY := group.Yes.BestBid()
+	noBid, okN := group.No.BestBid()
+	if !okY || !okN {
+		return ArbOpportunity{}, false
+	}
+
+	proceeds := yesBid.Price + noBid.Price
+	edge := proceeds - (1 + fees)
+	edgeBps := edge * 10000
+	if edgeBps <= d.cfg.MinEdgeBps {
+		return ArbOpportunity{}, false
+	}
+
+	size := maxExecutableSize(group.Yes.View().Bids, group.No.View().Bids, 1+fees, false)
+	return ArbOpportunity{
+		Timestamp:   at,
+		ConditionID: group.ConditionID,
+		Direction:   ArbSellBoth,
+		Size:        size,
+		EdgeBps:     edgeBps,
+	}, true
+}
+
+// maxExecutableSize walks both legs level by level, matched 1:1 since one
+// YES share must be bought/sold against one NO share, accumulating size
+// on both legs while their combined VWAP still clears limit (<=limit for
+// a buy-both, >=limit for a sell-both). It returns the largest size at
+// which the invariant still holds, including a fractional final level
+// found via binary search, rather than stopping at the first level.
+func maxExecutableSize(legA, legB []BookLevel, limit float64, buy bool) float64 {
+	clears := func(combined float64) bool {
+		if buy {
+			return combined <= limit
+		}
+		return combined >= limit
+	}
+
+	var i, j int
+	var size, notionalA, notionalB float64
+	var remA, remB float64
+
+	for i < len(legA) && j < len(legB) {
+		if remA == 0 {
+			remA = legA[i].Size
+		}
+		if remB == 0 {
+			remB = legB[j].Size
+		}
+		priceA, priceB := legA[i].Price, legB[j].Price
+
+		candidate := remA
+		if remB < candidate {
+			candidate = remB
+		}
+
+		trialSize := size + candidate
+		trialNotionalA := notionalA + candidate*priceA
+		trialNotionalB := notionalB + candidate*priceB
+		combined := trialNotionalA/trialSize + trialNotionalB/trialSize
+
+		if clears(combined) {
+			size, notionalA, notionalB = trialSize, trialNotionalA, trialNotionalB
+			remA -= candidate
+			remB -= candidate
+			if remA == 0 {
+				i++
+			}
+			if remB == 0 {
+				j++
+			}
+			continue
+		}
+
+		// This increment's full size breaks the invariant; binary search
+		// the largest partial fill of it that still clears.
+		lo, hi := 0.0, candidate
+		for k := 0; k < 40; k++ {
+			mid := (lo + hi) / 2
+			tSize := size + mid
+			if tSize == 0 {
+				lo = mid
+				continue
+			}
+			tA := notionalA + mid*priceA
+			tB := notionalB + mid*priceB
+			if clears(tA/tSize + tB/tSize) {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		size += lo
+		break
+	}
+
+	return size
+}
@@ -0,0 +1,18 @@
+package main
+
+import "flag"
+
+// Flags tuning how aggressively the YES/NO arbitrage detector reports
+// opportunities.
+var (
+	flagArbFeesBps    = flag.Float64("arb-fees-bps", 200, "round-trip fee budget (in bps) subtracted from the $1 YES/NO invariant")
+	flagArbMinEdgeBps = flag.Float64("arb-min-edge-bps", 50, "minimum edge (in bps) required before an ArbOpportunity is reported")
+)
+
+// ArbDetectorFromFlags builds an ArbDetector from --arb-fees-bps/--arb-min-edge-bps.
+func ArbDetectorFromFlags(bus *EventBus) *ArbDetector {
+	return NewArbDetector(ArbDetectorConfig{
+		FeesBps:    *flagArbFeesBps,
+		MinEdgeBps: *flagArbMinEdgeBps,
+	}, bus)
+}
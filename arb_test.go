@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+func bookWithLevels(assetID string, bids, asks []BookLevel) *Book {
+	b := NewBook(assetID, 0.01, nil)
+	b.bids.replace(bids)
+	b.asks.replace(asks)
+	return b
+}
+
+func TestEvaluateBuyBothDetectsUnderOneDollar(t *testing.T) {
+	yes := bookWithLevels("yes", nil, []BookLevel{{Price: 0.45, Size: 100}})
+	no := bookWithLevels("no", nil, []BookLevel{{Price: 0.40, Size: 100}})
+	group := NewBookGroup("cond-1", yes, no)
+
+	d := NewArbDetector(ArbDetectorConfig{FeesBps: 0, MinEdgeBps: 0}, nil)
+	opp, ok := d.evaluateBuyBoth(group, 0, testTime)
+	if !ok {
+		t.Fatal("evaluateBuyBoth() ok = false, want true (0.45+0.40 < 1)")
+	}
+	if opp.Direction != ArbBuyBoth {
+		t.Errorf("Direction = %v, want ArbBuyBoth", opp.Direction)
+	}
+	wantEdgeBps := (1 - 0.85) * 10000
+	if diff := opp.EdgeBps - wantEdgeBps; diff > 1e-6 || diff < -1e-6 {
+		t.Errorf("EdgeBps = %v, want %v", opp.EdgeBps, wantEdgeBps)
+	}
+}
+
+func TestEvaluateBuyBothBelowMinEdgeIsSuppressed(t *testing.T) {
+	yes := bookWithLevels("yes", nil, []BookLevel{{Price: 0.499, Size: 100}})
+	no := bookWithLevels("no", nil, []BookLevel{{Price: 0.499, Size: 100}})
+	group := NewBookGroup("cond-1", yes, no)
+
+	d := NewArbDetector(ArbDetectorConfig{FeesBps: 0, MinEdgeBps: 50}, nil)
+	if _, ok := d.evaluateBuyBoth(group, 0, testTime); ok {
+		t.Error("evaluateBuyBoth() ok = true, want false (edge below MinEdgeBps)")
+	}
+}
+
+func TestEvaluateBuyBothNoOpportunityAtOrAboveOneDollar(t *testing.T) {
+	yes := bookWithLevels("yes", nil, []BookLevel{{Price: 0.50, Size: 100}})
+	no := bookWithLevels("no", nil, []BookLevel{{Price: 0.55, Size: 100}})
+	group := NewBookGroup("cond-1", yes, no)
+
+	d := NewArbDetector(ArbDetectorConfig{FeesBps: 0, MinEdgeBps: 0}, nil)
+	if _, ok := d.evaluateBuyBoth(group, 0, testTime); ok {
+		t.Error("evaluateBuyBoth() ok = true, want false (0.50+0.55 >= 1)")
+	}
+}
+
+func TestEvaluateSellBothDetectsOverOneDollar(t *testing.T) {
+	yes := bookWithLevels("yes", []BookLevel{{Price: 0.60, Size: 100}}, nil)
+	no := bookWithLevels("no", []BookLevel{{Price: 0.55, Size: 100}}, nil)
+	group := NewBookGroup("cond-1", yes, no)
+
+	d := NewArbDetector(ArbDetectorConfig{FeesBps: 0, MinEdgeBps: 0}, nil)
+	opp, ok := d.evaluateSellBoth(group, 0, testTime)
+	if !ok {
+		t.Fatal("evaluateSellBoth() ok = false, want true (0.60+0.55 > 1)")
+	}
+	if opp.Direction != ArbSellBoth {
+		t.Errorf("Direction = %v, want ArbSellBoth", opp.Direction)
+	}
+}
+
+func TestMaxExecutableSizeWalksBothBooksAndPartiallyFillsLastLevel(t *testing.T) {
+	// Buying both: combined ask VWAP must stay <= limit. The first level on
+	// each leg clears; the second A level alone would push the VWAP over
+	// the limit, so only a fractional fill of it should be included.
+	legA := []BookLevel{{Price: 0.40, Size: 50}, {Price: 0.55, Size: 50}}
+	legB := []BookLevel{{Price: 0.40, Size: 100}}
+
+	size := maxExecutableSize(legA, legB, 0.85, true)
+	if size <= 50 {
+		t.Errorf("maxExecutableSize() = %v, want > 50 (should walk into the second A level)", size)
+	}
+	if size >= 100 {
+		t.Errorf("maxExecutableSize() = %v, want < 100 (second level can only be partially filled)", size)
+	}
+}
+
+func TestMaxExecutableSizeZeroWhenFirstLevelAlreadyBreaksInvariant(t *testing.T) {
+	legA := []BookLevel{{Price: 0.60, Size: 100}}
+	legB := []BookLevel{{Price: 0.60, Size: 100}}
+
+	size := maxExecutableSize(legA, legB, 1.0, true)
+	if size != 0 {
+		t.Errorf("maxExecutableSize() = %v, want 0 (0.60+0.60 > 1.0 even at the first unit)", size)
+	}
+}
+
+func TestMaxExecutableSizeLimitedByThinnerLeg(t *testing.T) {
+	legA := []BookLevel{{Price: 0.30, Size: 10}}
+	legB := []BookLevel{{Price: 0.30, Size: 1000}}
+
+	size := maxExecutableSize(legA, legB, 1.0, true)
+	if size != 10 {
+		t.Errorf("maxExecutableSize() = %v, want 10 (capped by the thinner leg A)", size)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// priceLevels is a sorted slice of BookLevel for one side of a Book. It's
+// kept sorted by Price (descending for bids, ascending for asks) so
+// best-of-book reads are O(1) and updates find their level in O(log N) via
+// binary search, then pay an O(N) slice insert/delete; this is fine for a
+// list of a few hundred live price levels.
+type priceLevels struct {
+	levels []BookLevel
+	desc   bool // true for bids (best = highest price first)
+}
+
+func newPriceLevels(desc bool) *priceLevels {
+	return &priceLevels{desc: desc}
+}
+
+// set applies a price_change-style upsert: size 0 removes the level,
+// any other size inserts or replaces it.
+func (p *priceLevels) set(price, size float64) {
+	i := p.search(price)
+	if i < len(p.levels) && p.levels[i].Price == price {
+		if size == 0 {
+			p.levels = append(p.levels[:i], p.levels[i+1:]...)
+		} else {
+			p.levels[i].Size = size
+		}
+		return
+	}
+	if size == 0 {
+		return
+	}
+	p.levels = append(p.levels, BookLevel{})
+	copy(p.levels[i+1:], p.levels[i:])
+	p.levels[i] = BookLevel{Price: price, Size: size}
+}
+
+// search returns the index at which price belongs, per the side's sort
+// order, using binary search.
+func (p *priceLevels) search(price float64) int {
+	return sort.Search(len(p.levels), func(i int) bool {
+		if p.desc {
+			return p.levels[i].Price <= price
+		}
+		return p.levels[i].Price >= price
+	})
+}
+
+func (p *priceLevels) best() (BookLevel, bool) {
+	if len(p.levels) == 0 {
+		return BookLevel{}, false
+	}
+	return p.levels[0], true
+}
+
+func (p *priceLevels) snapshot() []BookLevel {
+	out := make([]BookLevel, len(p.levels))
+	copy(out, p.levels)
+	return out
+}
+
+func (p *priceLevels) replace(levels []BookLevel) {
+	sorted := append([]BookLevel(nil), levels...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if p.desc {
+			return sorted[i].Price > sorted[j].Price
+		}
+		return sorted[i].Price < sorted[j].Price
+	})
+	p.levels = sorted
+}
+
+// Book is an in-memory L2 order book for a single asset_id (token_id),
+// kept up to date from the market channel's book, price_change, and
+// tick_size_change messages.
+type Book struct {
+	mu       sync.RWMutex
+	AssetID  string
+	TickSize float64
+	bids     *priceLevels
+	asks     *priceLevels
+	bus      *EventBus
+}
+
+// NewBook creates an empty Book for assetID. bus may be nil, in which
+// case the book maintains state but publishes nothing.
+func NewBook(assetID string, tickSize float64, bus *EventBus) *Book {
+	return &Book{
+		AssetID:  assetID,
+		TickSize: tickSize,
+		bids:     newPriceLevels(true),
+		asks:     newPriceLevels(false),
+		bus:      bus,
+	}
+}
+
+// publish is a no-op when no bus was configured, so Apply* methods don't
+// need to branch on b.bus themselves.
+func (b *Book) publish(ev Event) {
+	if b.bus == nil {
+		return
+	}
+	ev.AssetID = b.AssetID
+	ev.Timestamp = time.Now()
+	b.bus.Publish(ev)
+}
+
+// wsLevel mirrors one [price, size] entry as Polymarket's market channel
+// encodes it (both fields as strings).
+type wsLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+func (l wsLevel) parse() (BookLevel, error) {
+	var lvl BookLevel
+	if _, err := fmt.Sscanf(l.Price, "%f", &lvl.Price); err != nil {
+		return lvl, fmt.Errorf("parse price %q: %w", l.Price, err)
+	}
+	if _, err := fmt.Sscanf(l.Size, "%f", &lvl.Size); err != nil {
+		return lvl, fmt.Errorf("parse size %q: %w", l.Size, err)
+	}
+	return lvl, nil
+}
+
+// ApplySnapshot replaces the full book from a "book" message payload.
+func (b *Book) ApplySnapshot(raw []byte) error {
+	var msg struct {
+		Bids []wsLevel `json:"bids"`
+		Asks []wsLevel `json:"asks"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		b.publish(Event{Type: EventDecodeError})
+		return fmt.Errorf("book %s: decode snapshot: %w", b.AssetID, err)
+	}
+
+	bids, err := parseLevels(msg.Bids)
+	if err != nil {
+		b.publish(Event{Type: EventDecodeError})
+		return fmt.Errorf("book %s: %w", b.AssetID, err)
+	}
+	asks, err := parseLevels(msg.Asks)
+	if err != nil {
+		b.publish(Event{Type: EventDecodeError})
+		return fmt.Errorf("book %s: %w", b.AssetID, err)
+	}
+
+	b.mu.Lock()
+	b.bids.replace(bids)
+	b.asks.replace(asks)
+	b.mu.Unlock()
+
+	b.publish(Event{Type: EventBookSnapshot, Payload: b.View()})
+	return nil
+}
+
+// ApplyPriceChange applies a "price_change" message's level upserts to
+// the relevant side of the book.
+func (b *Book) ApplyPriceChange(raw []byte) error {
+	var msg struct {
+		Changes []struct {
+			Side  string `json:"side"` // "BUY" or "SELL"
+			Price string `json:"price"`
+			Size  string `json:"size"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		b.publish(Event{Type: EventDecodeError})
+		return fmt.Errorf("book %s: decode price_change: %w", b.AssetID, err)
+	}
+
+	b.mu.Lock()
+	for _, c := range msg.Changes {
+		lvl, err := wsLevel{Price: c.Price, Size: c.Size}.parse()
+		if err != nil {
+			b.mu.Unlock()
+			b.publish(Event{Type: EventDecodeError})
+			return fmt.Errorf("book %s: %w", b.AssetID, err)
+		}
+		switch c.Side {
+		case "BUY":
+			b.bids.set(lvl.Price, lvl.Size)
+		case "SELL":
+			b.asks.set(lvl.Price, lvl.Size)
+		default:
+			b.mu.Unlock()
+			b.publish(Event{Type: EventDecodeError})
+			return fmt.Errorf("book %s: unknown side %q", b.AssetID, c.Side)
+		}
+	}
+	b.mu.Unlock()
+
+	b.publish(Event{Type: EventPriceChange, Payload: b.View()})
+	b.publish(Event{Type: EventBookDelta, Payload: b.View()})
+	return nil
+}
+
+// ApplyTickSizeChange updates the book's tick size from a
+// "tick_size_change" message.
+func (b *Book) ApplyTickSizeChange(raw []byte) error {
+	var msg struct {
+		NewTickSize string `json:"new_tick_size"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		b.publish(Event{Type: EventDecodeError})
+		return fmt.Errorf("book %s: decode tick_size_change: %w", b.AssetID, err)
+	}
+	var tick float64
+	if _, err := fmt.Sscanf(msg.NewTickSize, "%f", &tick); err != nil {
+		b.publish(Event{Type: EventDecodeError})
+		return fmt.Errorf("book %s: parse new_tick_size %q: %w", b.AssetID, msg.NewTickSize, err)
+	}
+
+	b.mu.Lock()
+	b.TickSize = tick
+	b.mu.Unlock()
+
+	b.publish(Event{Type: EventTickSizeChange, Payload: tick})
+	return nil
+}
+
+func parseLevels(raw []wsLevel) ([]BookLevel, error) {
+	out := make([]BookLevel, len(raw))
+	for i, l := range raw {
+		lvl, err := l.parse()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = lvl
+	}
+	return out, nil
+}
+
+// View returns a read-only snapshot of the book suitable for
+// PriceAggregator.Update and other analytics that shouldn't hold the
+// book's lock while they run.
+func (b *Book) View() BookView {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return BookView{
+		AssetID: b.AssetID,
+		Bids:    b.bids.snapshot(),
+		Asks:    b.asks.snapshot(),
+	}
+}
+
+// BestBid returns the current best bid, if any.
+func (b *Book) BestBid() (BookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.bids.best()
+}
+
+// BestAsk returns the current best ask, if any.
+func (b *Book) BestAsk() (BookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.asks.best()
+}
+
+// BookGroup links the YES and NO outcome token books for a single
+// condition_id, which is what makes cross-market arbitrage detection
+// possible on Polymarket's binary-outcome markets.
+type BookGroup struct {
+	ConditionID string
+	Yes         *Book
+	No          *Book
+}
+
+// NewBookGroup pairs the YES and NO books for one condition_id.
+func NewBookGroup(conditionID string, yes, no *Book) *BookGroup {
+	return &BookGroup{ConditionID: conditionID, Yes: yes, No: no}
+}
@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestPriceLevelsSetInsertsInSortOrder(t *testing.T) {
+	bids := newPriceLevels(true) // descending: best (highest) first
+	bids.set(0.50, 10)
+	bids.set(0.60, 20)
+	bids.set(0.55, 30)
+
+	got := bids.snapshot()
+	want := []BookLevel{{Price: 0.60, Size: 20}, {Price: 0.55, Size: 30}, {Price: 0.50, Size: 10}}
+	if len(got) != len(want) {
+		t.Fatalf("snapshot() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("snapshot()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPriceLevelsSetReplacesExistingLevel(t *testing.T) {
+	asks := newPriceLevels(false)
+	asks.set(0.50, 10)
+	asks.set(0.50, 25)
+
+	got, ok := asks.best()
+	if !ok || got != (BookLevel{Price: 0.50, Size: 25}) {
+		t.Errorf("best() = %v, %v; want {0.50 25}, true", got, ok)
+	}
+}
+
+func TestPriceLevelsSetZeroSizeRemoves(t *testing.T) {
+	asks := newPriceLevels(false)
+	asks.set(0.50, 10)
+	asks.set(0.55, 20)
+	asks.set(0.50, 0) // removal
+
+	got := asks.snapshot()
+	if len(got) != 1 || got[0].Price != 0.55 {
+		t.Errorf("snapshot() = %v, want only the 0.55 level", got)
+	}
+}
+
+func TestPriceLevelsSetZeroSizeOnMissingLevelIsNoop(t *testing.T) {
+	asks := newPriceLevels(false)
+	asks.set(0.50, 0)
+
+	if got := asks.snapshot(); len(got) != 0 {
+		t.Errorf("snapshot() = %v, want empty", got)
+	}
+}
+
+func TestPriceLevelsBestEmpty(t *testing.T) {
+	if _, ok := newPriceLevels(true).best(); ok {
+		t.Error("best() on empty priceLevels returned ok = true")
+	}
+}
+
+func TestBookApplySnapshotThenPriceChange(t *testing.T) {
+	b := NewBook("asset-1", 0.01, nil)
+
+	snapshot := []byte(`{"bids":[{"price":"0.50","size":"100"}],"asks":[{"price":"0.55","size":"100"}]}`)
+	if err := b.ApplySnapshot(snapshot); err != nil {
+		t.Fatalf("ApplySnapshot() error = %v", err)
+	}
+
+	change := []byte(`{"changes":[{"side":"BUY","price":"0.52","size":"40"}]}`)
+	if err := b.ApplyPriceChange(change); err != nil {
+		t.Fatalf("ApplyPriceChange() error = %v", err)
+	}
+
+	bid, ok := b.BestBid()
+	if !ok || bid != (BookLevel{Price: 0.52, Size: 40}) {
+		t.Errorf("BestBid() = %v, %v; want {0.52 40}, true", bid, ok)
+	}
+}
+
+func TestBookApplyPriceChangeUnknownSide(t *testing.T) {
+	b := NewBook("asset-1", 0.01, nil)
+	bad := []byte(`{"changes":[{"side":"SIDEWAYS","price":"0.52","size":"40"}]}`)
+	if err := b.ApplyPriceChange(bad); err == nil {
+		t.Error("ApplyPriceChange() with an unknown side returned nil error")
+	}
+}
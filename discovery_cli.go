@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Flags for resolving markets to monitor without editing Go source. These
+// replace the old pattern of hardcoding asset IDs in example_config.go.
+var (
+	flagMarketSlug = flag.String("market-slug", "", "resolve and monitor all outcome tokens of the market with this slug")
+	flagEventID    = flag.String("event-id", "", "resolve and monitor all markets belonging to this event id")
+	flagCategory   = flag.String("category", "", "resolve and monitor all markets in this Gamma category")
+	flagKeyword    = flag.String("keyword", "", "resolve and monitor markets whose question matches this search term")
+	flagMinVolume  = flag.Float64("min-volume", 0, "only include markets with at least this much trading volume in category and keyword searches")
+	flagActiveOnly = flag.Bool("active-only", true, "exclude closed/archived markets from category and keyword searches")
+	flagOutcome    = flag.String("outcome", "", "restrict category and keyword searches to a single outcome (Yes/No), default both")
+	flagCacheDir   = flag.String("market-cache-dir", ".market-cache", "directory used to cache resolved market lookups")
+	flagCacheTTL   = flag.Duration("market-cache-ttl", 10*time.Minute, "how long cached market lookups remain valid")
+)
+
+// ResolveMarketsFromFlags resolves the set of markets to monitor based on
+// whichever discovery flags were supplied on the command line. At least
+// one of --market-slug, --event-id, --category, or --keyword must be set.
+func ResolveMarketsFromFlags() ([]Market, error) {
+	resolver := NewMarketResolver(*flagCacheDir, *flagCacheTTL)
+
+	switch {
+	case *flagMarketSlug != "":
+		return resolver.ResolveBySlug(*flagMarketSlug)
+	case *flagEventID != "":
+		return resolver.ResolveByEventID(*flagEventID)
+	case *flagCategory != "" || *flagKeyword != "":
+		return resolver.ResolveByQuery(MarketQuery{
+			Keyword:    *flagKeyword,
+			Category:   *flagCategory,
+			MinVolume:  *flagMinVolume,
+			ActiveOnly: *flagActiveOnly,
+			Outcome:    *flagOutcome,
+		})
+	default:
+		return nil, fmt.Errorf("no market discovery flags set: specify --market-slug, --event-id, --category, or --keyword")
+	}
+}
+
+// AssetIDsFromMarkets extracts the token IDs from resolved markets, for
+// callers that only want a flat asset ID list rather than the full Market
+// struct.
+func AssetIDsFromMarkets(markets []Market) []string {
+	ids := make([]string, len(markets))
+	for i, m := range markets {
+		ids[i] = m.TokenID
+	}
+	return ids
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies one of the normalized events published on an
+// EventBus.
+type EventType string
+
+const (
+	EventBookSnapshot   EventType = "book_snapshot"
+	EventBookDelta      EventType = "book_delta"
+	EventTrade          EventType = "trade"
+	EventPriceChange    EventType = "price_change"
+	EventTickSizeChange EventType = "tick_size_change"
+	EventReconnect      EventType = "reconnect"
+	// EventArbOpportunity carries an ArbOpportunity found by an
+	// ArbDetector, so Prometheus and other bus consumers see them
+	// alongside raw book/price events instead of only on its own channel.
+	EventArbOpportunity EventType = "arb_opportunity"
+	// EventDecodeError marks a message that failed to decode, so
+	// consumers like the Prometheus exporter can count them.
+	EventDecodeError EventType = "decode_error"
+)
+
+// Event is the normalized envelope published on an EventBus for
+// downstream consumers (such as the Prometheus exporter) to subscribe to
+// independently instead of each wiring itself directly into the WS client.
+type Event struct {
+	Type      EventType
+	AssetID   string
+	Timestamp time.Time
+	// ServerTime is the source message's own timestamp, used to compute
+	// message_latency_seconds against Timestamp (local receive time) when
+	// it is non-zero. None of the publishers in this package (Book,
+	// wsShard, ArbDetector, publishTrade) set it, so their events leave it
+	// zero and are not observed in that histogram.
+	ServerTime time.Time
+	Payload    interface{} // BookView, Trade, ArbOpportunity, a float64 tick size, or nil
+}
+
+// Trade is a normalized fill reported on the market channel's "last_trade_price" message.
+type Trade struct {
+	AssetID string
+	Price   float64
+	Size    float64
+	Side    string
+}
+
+// EventBus is a typed fan-out hub: one Publish reaches every current
+// subscriber. Subscribers that fall behind have events dropped for them
+// rather than blocking publishers, since live market data is only useful
+// while fresh.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[int]chan Event
+	nextID      int
+}
+
+// NewEventBus creates an empty EventBus.
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]chan Event)}
+}
+
+// Subscribe registers a new subscriber and returns its event channel and
+// an unsubscribe function. The channel is buffered; a slow subscriber has
+// events dropped rather than stalling Publish.
+func (b *EventBus) Subscribe(bufferSize int) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, bufferSize)
+	b.subscribers[id] = ch
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if ch, ok := b.subscribers[id]; ok {
+			delete(b.subscribers, id)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// Publish fans ev out to every current subscriber, dropping it for any
+// subscriber whose buffer is full.
+func (b *EventBus) Publish(ev Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
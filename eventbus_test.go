@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestEventBusFansOutToAllSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	chA, unsubA := bus.Subscribe(1)
+	defer unsubA()
+	chB, unsubB := bus.Subscribe(1)
+	defer unsubB()
+
+	bus.Publish(Event{Type: EventReconnect, AssetID: "asset-1"})
+
+	for name, ch := range map[string]<-chan Event{"A": chA, "B": chB} {
+		select {
+		case ev := <-ch:
+			if ev.AssetID != "asset-1" {
+				t.Errorf("subscriber %s got AssetID %q, want asset-1", name, ev.AssetID)
+			}
+		default:
+			t.Errorf("subscriber %s received nothing", name)
+		}
+	}
+}
+
+func TestEventBusDropsOnFullBuffer(t *testing.T) {
+	bus := NewEventBus()
+	ch, unsub := bus.Subscribe(1)
+	defer unsub()
+
+	bus.Publish(Event{Type: EventReconnect, AssetID: "first"})
+	bus.Publish(Event{Type: EventReconnect, AssetID: "second"}) // buffer full, dropped rather than blocking
+
+	ev := <-ch
+	if ev.AssetID != "first" {
+		t.Errorf("AssetID = %q, want first", ev.AssetID)
+	}
+	select {
+	case ev := <-ch:
+		t.Errorf("got unexpected second event %+v; publish should have dropped it", ev)
+	default:
+	}
+}
+
+func TestEventBusUnsubscribeClosesChannel(t *testing.T) {
+	bus := NewEventBus()
+	ch, unsub := bus.Subscribe(1)
+	unsub()
+
+	if _, ok := <-ch; ok {
+		t.Error("channel still open after unsubscribe")
+	}
+
+	// Publishing after unsubscribe must not panic or deliver anything.
+	bus.Publish(Event{Type: EventReconnect})
+}
@@ -1,7 +1,11 @@
 package main
 
 // This file contains example configurations and helper functions
-// for customizing your Polymarket orderbook listener
+// for customizing your Polymarket orderbook listener.
+//
+// Prefer MarketResolver (market.go) and the --market-slug/--category/
+// --keyword flags (discovery_cli.go) for real usage; these presets are
+// kept only as a zero-config fallback and for quick manual testing.
 
 // Example asset IDs for different markets
 // Replace these with actual asset IDs from Polymarket
@@ -9,7 +13,7 @@ package main
 var (
 	// Example from Polymarket documentation
 	ExampleAssetID = "109681959945973300464568698402968596289258214226684818748321941747028805721376"
-	
+
 	// Add your own asset IDs here
 	// You can find these by:
 	// 1. Visiting a market on polymarket.com
@@ -22,7 +26,7 @@ var PresetConfigurations = map[string][]string{
 	"single_market": {
 		ExampleAssetID,
 	},
-	
+
 	"multiple_markets": {
 		// Add multiple asset IDs to monitor several markets at once
 		ExampleAssetID,
@@ -40,17 +44,16 @@ func GetAssetIDsFromPreset(presetName string) []string {
 }
 
 // Example usage in main.go:
-// 
+//
 // func main() {
 //     // Use a preset configuration
 //     assetIDs := GetAssetIDsFromPreset("single_market")
-//     
+//
 //     // Or define custom asset IDs
 //     // assetIDs := []string{
 //     //     "YOUR_ASSET_ID_HERE",
 //     // }
-//     
+//
 //     client := NewPolymarketClient(assetIDs, nil)
 //     // ... rest of the code
 // }
-
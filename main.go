@@ -0,0 +1,326 @@
+// Command polymarket-orderbook-history resolves a set of markets (or
+// replays a previously captured archive), maintains their L2 order
+// books, watches YES/NO pairs for arbitrage, and exposes the whole
+// thing over Prometheus metrics — wiring together the pieces in
+// market.go, discovery_cli.go, book.go, arb.go, subscription_manager.go,
+// replayer.go, and metrics_server.go into one runnable binary.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	flag.Parse()
+
+	bus := NewEventBus()
+	metricsServer := StartMetricsServerFromFlags(bus)
+	arbDetector := ArbDetectorFromFlags(bus)
+	go logArbOpportunities(arbDetector)
+
+	aggregator, err := PriceAggregatorFromFlags()
+	if err != nil {
+		log.Fatalf("price aggregator: %v", err)
+	}
+	defer aggregator.Close()
+	go logPriceTicks(aggregator)
+
+	store, err := SnapshotStoreFromFlags()
+	if err != nil {
+		log.Fatalf("capture: %v", err)
+	}
+	if store != nil {
+		defer store.Close()
+	}
+
+	if replayer, err := ReplayerFromFlags(); err != nil {
+		log.Fatalf("replay: %v", err)
+	} else if replayer != nil {
+		runReplay(replayer, bus)
+		return
+	}
+
+	markets, err := marketsToMonitor()
+	if err != nil {
+		log.Fatalf("resolve markets: %v", err)
+	}
+
+	books := make(map[string]*Book, len(markets))
+	for _, m := range markets {
+		books[m.TokenID] = NewBook(m.TokenID, m.TickSize, bus)
+	}
+	bookToGroup := pairBookGroups(markets, books)
+
+	onMessage := func(assetID string, raw []byte) {
+		eventType, err := messageEventType(raw)
+		if err != nil {
+			log.Printf("decode message for %s: %v", assetID, err)
+			return
+		}
+
+		if store != nil {
+			if err := store.Write(toBookEvent(assetID, eventType, raw, time.Now())); err != nil {
+				log.Printf("capture %s: %v", assetID, err)
+			}
+		}
+
+		if eventType == "last_trade_price" {
+			if err := publishTrade(bus, assetID, raw); err != nil {
+				log.Printf("trade %s: %v", assetID, err)
+			}
+		}
+
+		book, ok := books[assetID]
+		if !ok {
+			return
+		}
+		if err := routeMessage(book, eventType, raw); err != nil {
+			log.Printf("apply message for %s: %v", assetID, err)
+			return
+		}
+		if group, ok := bookToGroup[assetID]; ok {
+			arbDetector.Evaluate(group, time.Now())
+		}
+		if err := aggregator.Update(book.View(), time.Now()); err != nil {
+			log.Printf("price aggregator %s: %v", assetID, err)
+		}
+	}
+	onReconnect := func(assetID string, snapshot []byte) {
+		if book, ok := books[assetID]; ok {
+			if err := book.ApplySnapshot(snapshot); err != nil {
+				log.Printf("reconcile %s: %v", assetID, err)
+			}
+		}
+	}
+
+	subMgr := NewSubscriptionManager(onMessage, onReconnect, bus)
+	for assetID := range books {
+		if err := subMgr.Subscribe(assetID); err != nil {
+			log.Printf("subscribe %s: %v", assetID, err)
+		}
+	}
+	if metricsServer != nil {
+		go pollShardMetrics(metricsServer, subMgr)
+	}
+
+	waitForShutdown()
+	if err := subMgr.Close(); err != nil {
+		log.Printf("close subscription manager: %v", err)
+	}
+}
+
+// marketsToMonitor resolves markets from the discovery flags, falling
+// back to the legacy single_market preset (example_config.go) when none
+// of --market-slug/--event-id/--category/--keyword were supplied, so the
+// binary still runs out of the box.
+func marketsToMonitor() ([]Market, error) {
+	if *flagMarketSlug == "" && *flagEventID == "" && *flagCategory == "" && *flagKeyword == "" {
+		assetIDs := GetAssetIDsFromPreset("single_market")
+		fallback := make([]Market, len(assetIDs))
+		for i, id := range assetIDs {
+			fallback[i] = Market{TokenID: id, TickSize: 0.01}
+		}
+		return fallback, nil
+	}
+	return ResolveMarketsFromFlags()
+}
+
+// pairBookGroups links each resolved market's book to the BookGroup for
+// its condition_id, so onMessage can re-run the arb detector whenever
+// either side of a YES/NO pair updates.
+func pairBookGroups(markets []Market, books map[string]*Book) map[string]*BookGroup {
+	yes := make(map[string]*Book)
+	no := make(map[string]*Book)
+	for _, m := range markets {
+		switch strings.ToUpper(m.Outcome) {
+		case "YES":
+			yes[m.ConditionID] = books[m.TokenID]
+		case "NO":
+			no[m.ConditionID] = books[m.TokenID]
+		}
+	}
+
+	bookToGroup := make(map[string]*BookGroup)
+	for conditionID, yesBook := range yes {
+		noBook, ok := no[conditionID]
+		if !ok {
+			continue
+		}
+		group := NewBookGroup(conditionID, yesBook, noBook)
+		bookToGroup[yesBook.AssetID] = group
+		bookToGroup[noBook.AssetID] = group
+	}
+	return bookToGroup
+}
+
+// messageEventType pulls the event_type field out of a raw market-channel
+// message, the same envelope field extractAssetID reads asset_id from.
+func messageEventType(raw []byte) (string, error) {
+	var envelope struct {
+		EventType string `json:"event_type"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return "", err
+	}
+	return envelope.EventType, nil
+}
+
+// routeMessage applies a raw market-channel message to book based on
+// eventType, ignoring message types that don't affect book state.
+// last_trade_price is handled separately by publishTrade, since a trade
+// carries no book state to apply.
+func routeMessage(book *Book, eventType string, raw []byte) error {
+	switch eventType {
+	case "book":
+		return book.ApplySnapshot(raw)
+	case "price_change":
+		return book.ApplyPriceChange(raw)
+	case "tick_size_change":
+		return book.ApplyTickSizeChange(raw)
+	default:
+		return nil
+	}
+}
+
+// toBookEvent flattens a raw market-channel message into the BookEvent
+// schema SnapshotStore persists, pulling out side/price/size where the
+// message type carries a single one (price_change, last_trade_price);
+// the full message is always preserved in Raw regardless.
+func toBookEvent(assetID, eventType string, raw []byte, at time.Time) BookEvent {
+	ev := BookEvent{
+		AssetID:   assetID,
+		EventType: eventType,
+		Timestamp: at.UnixMilli(),
+		Raw:       string(raw),
+	}
+
+	switch eventType {
+	case "price_change":
+		var msg struct {
+			Changes []struct {
+				Side  string `json:"side"`
+				Price string `json:"price"`
+				Size  string `json:"size"`
+			} `json:"changes"`
+		}
+		if json.Unmarshal(raw, &msg) == nil && len(msg.Changes) > 0 {
+			ev.Side = msg.Changes[0].Side
+			ev.Price, _ = strconv.ParseFloat(msg.Changes[0].Price, 64)
+			ev.Size, _ = strconv.ParseFloat(msg.Changes[0].Size, 64)
+		}
+	case "last_trade_price":
+		var msg struct {
+			Side  string `json:"side"`
+			Price string `json:"price"`
+			Size  string `json:"size"`
+		}
+		if json.Unmarshal(raw, &msg) == nil {
+			ev.Side = msg.Side
+			ev.Price, _ = strconv.ParseFloat(msg.Price, 64)
+			ev.Size, _ = strconv.ParseFloat(msg.Size, 64)
+		}
+	}
+	return ev
+}
+
+// publishTrade decodes a "last_trade_price" message and publishes it on
+// bus as an EventTrade. Trades carry no book state, so unlike
+// price_change/tick_size_change they're never routed into a Book.
+func publishTrade(bus *EventBus, assetID string, raw []byte) error {
+	var msg struct {
+		Side  string `json:"side"`
+		Price string `json:"price"`
+		Size  string `json:"size"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return fmt.Errorf("decode trade for %s: %w", assetID, err)
+	}
+	price, err := strconv.ParseFloat(msg.Price, 64)
+	if err != nil {
+		return fmt.Errorf("parse trade price for %s: %w", assetID, err)
+	}
+	size, err := strconv.ParseFloat(msg.Size, 64)
+	if err != nil {
+		return fmt.Errorf("parse trade size for %s: %w", assetID, err)
+	}
+
+	bus.Publish(Event{
+		Type:      EventTrade,
+		AssetID:   assetID,
+		Timestamp: time.Now(),
+		Payload:   Trade{AssetID: assetID, Price: price, Size: size, Side: msg.Side},
+	})
+	return nil
+}
+
+// runReplay feeds a Replayer's BookEvents into per-asset Books (created
+// lazily, since a replay archive doesn't carry tick size), so offline
+// analytics can be developed against the exact same Apply* path the live
+// feed uses.
+func runReplay(r *Replayer, bus *EventBus) {
+	go func() {
+		if err := r.Run(); err != nil {
+			log.Printf("replay: %v", err)
+		}
+	}()
+
+	books := make(map[string]*Book)
+	for ev := range r.Events {
+		if ev.Raw == "" {
+			continue
+		}
+		if ev.EventType == "last_trade_price" {
+			if err := publishTrade(bus, ev.AssetID, []byte(ev.Raw)); err != nil {
+				log.Printf("replay trade %s: %v", ev.AssetID, err)
+			}
+			continue
+		}
+		book, ok := books[ev.AssetID]
+		if !ok {
+			book = NewBook(ev.AssetID, 0.01, bus)
+			books[ev.AssetID] = book
+		}
+		if err := routeMessage(book, ev.EventType, []byte(ev.Raw)); err != nil {
+			log.Printf("replay %s: %v", ev.AssetID, err)
+		}
+	}
+}
+
+func logArbOpportunities(d *ArbDetector) {
+	for opp := range d.Opportunities {
+		log.Printf("arb opportunity: condition=%s direction=%s size=%.4f edge_bps=%.2f",
+			opp.ConditionID, opp.Direction, opp.Size, opp.EdgeBps)
+	}
+}
+
+// pollShardMetrics periodically pushes SubscriptionManager.Metrics() into
+// the Prometheus exporter's polymarket_shard_* gauges, since shard health
+// isn't carried through the EventBus like per-asset events are.
+func pollShardMetrics(m *MetricsServer, subMgr *SubscriptionManager) {
+	const interval = 10 * time.Second
+	for range time.Tick(interval) {
+		m.UpdateShardMetrics(subMgr.Metrics())
+	}
+}
+
+func logPriceTicks(a *PriceAggregator) {
+	for tick := range a.Ticks() {
+		log.Printf("price tick: asset=%s strategy=%s price=%.4f spread=%.4f depth=%.2f",
+			tick.AssetID, tick.Strategy, tick.Price, tick.Spread, tick.DepthUsed)
+	}
+}
+
+func waitForShutdown() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+}
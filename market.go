@@ -0,0 +1,346 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Gamma and CLOB base URLs. Overridable for testing against a mock server.
+var (
+	GammaBaseURL = "https://gamma-api.polymarket.com"
+	CLOBBaseURL  = "https://clob.polymarket.com"
+)
+
+// Market describes a single tradable outcome token resolved from the
+// Gamma/CLOB APIs. It carries everything the WS subscriber and orderbook
+// decoder need downstream, so callers never have to go back to the REST
+// APIs once a market has been resolved.
+type Market struct {
+	TokenID     string    `json:"token_id"`
+	ConditionID string    `json:"condition_id"`
+	Outcome     string    `json:"outcome"`
+	Question    string    `json:"question"`
+	Slug        string    `json:"slug"`
+	Category    string    `json:"category"`
+	TickSize    float64   `json:"tick_size"`
+	NegRisk     bool      `json:"neg_risk"`
+	Active      bool      `json:"active"`
+	Volume      float64   `json:"volume"`
+	CloseTime   time.Time `json:"close_time"`
+}
+
+// MarketQuery filters candidate markets when resolving by category or
+// a free-text search instead of an exact slug/event ID.
+type MarketQuery struct {
+	Keyword     string
+	Category    string
+	MinVolume   float64
+	CloseBefore time.Time
+	CloseAfter  time.Time
+	ActiveOnly  bool
+	Outcome     string // "Yes", "No", or "" for both
+}
+
+// MarketResolver resolves human-friendly market references (slugs,
+// categories, event IDs, or free-text queries) into concrete Market
+// records by querying the Gamma markets endpoint and the CLOB /markets
+// endpoint. Results are cached to disk so repeated runs don't hammer
+// either API.
+type MarketResolver struct {
+	httpClient *http.Client
+	cache      *marketCache
+}
+
+// NewMarketResolver builds a MarketResolver. cacheDir may be empty, in
+// which case caching is disabled and every call hits the network.
+func NewMarketResolver(cacheDir string, ttl time.Duration) *MarketResolver {
+	var cache *marketCache
+	if cacheDir != "" {
+		cache = newMarketCache(cacheDir, ttl)
+	}
+	return &MarketResolver{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		cache:      cache,
+	}
+}
+
+// ResolveBySlug resolves every outcome token for a single market, looked
+// up by its Polymarket URL slug (e.g. "will-x-happen-in-2026").
+func (r *MarketResolver) ResolveBySlug(slug string) ([]Market, error) {
+	cacheKey := "slug:" + slug
+	if markets, ok := r.cacheGet(cacheKey); ok {
+		return markets, nil
+	}
+
+	var raw []gammaMarket
+	if err := r.getJSON(GammaBaseURL+"/markets", url.Values{"slug": {slug}}, &raw); err != nil {
+		return nil, fmt.Errorf("resolve slug %q: %w", slug, err)
+	}
+	markets, err := r.expandGammaMarkets(raw)
+	if err != nil {
+		return nil, fmt.Errorf("resolve slug %q: %w", slug, err)
+	}
+	r.cacheSet(cacheKey, markets)
+	return markets, nil
+}
+
+// ResolveByEventID resolves every market (and every outcome token within
+// each market) belonging to a single Polymarket event ID.
+func (r *MarketResolver) ResolveByEventID(id string) ([]Market, error) {
+	cacheKey := "event:" + id
+	if markets, ok := r.cacheGet(cacheKey); ok {
+		return markets, nil
+	}
+
+	var raw []gammaMarket
+	if err := r.getJSON(GammaBaseURL+"/markets", url.Values{"event_id": {id}}, &raw); err != nil {
+		return nil, fmt.Errorf("resolve event %q: %w", id, err)
+	}
+	markets, err := r.expandGammaMarkets(raw)
+	if err != nil {
+		return nil, fmt.Errorf("resolve event %q: %w", id, err)
+	}
+	r.cacheSet(cacheKey, markets)
+	return markets, nil
+}
+
+// ResolveByCategory resolves every market tagged with the given Gamma
+// category (e.g. "Politics", "Sports"). When activeOnly is true, closed
+// or archived markets are filtered out.
+func (r *MarketResolver) ResolveByCategory(cat string, activeOnly bool) ([]Market, error) {
+	return r.ResolveByQuery(MarketQuery{Category: cat, ActiveOnly: activeOnly})
+}
+
+// ResolveByQuery resolves markets matching an arbitrary combination of
+// keyword, category, volume, and close-date filters.
+func (r *MarketResolver) ResolveByQuery(q MarketQuery) ([]Market, error) {
+	cacheKey := "query:" + q.cacheKey()
+	if markets, ok := r.cacheGet(cacheKey); ok {
+		return markets, nil
+	}
+
+	params := url.Values{}
+	if q.Category != "" {
+		params.Set("category", q.Category)
+	}
+	if q.ActiveOnly {
+		params.Set("active", "true")
+	}
+	if q.Keyword != "" {
+		params.Set("search", q.Keyword)
+	}
+
+	var raw []gammaMarket
+	if err := r.getJSON(GammaBaseURL+"/markets", params, &raw); err != nil {
+		return nil, fmt.Errorf("resolve query: %w", err)
+	}
+
+	filtered := raw[:0]
+	for _, m := range raw {
+		if q.MinVolume > 0 && m.Volume64() < q.MinVolume {
+			continue
+		}
+		if !q.CloseBefore.IsZero() && m.EndDate().After(q.CloseBefore) {
+			continue
+		}
+		if !q.CloseAfter.IsZero() && m.EndDate().Before(q.CloseAfter) {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+
+	markets, err := r.expandGammaMarkets(filtered)
+	if err != nil {
+		return nil, fmt.Errorf("resolve query: %w", err)
+	}
+	if q.Outcome != "" {
+		markets = filterOutcome(markets, q.Outcome)
+	}
+	r.cacheSet(cacheKey, markets)
+	return markets, nil
+}
+
+func filterOutcome(markets []Market, outcome string) []Market {
+	out := markets[:0]
+	for _, m := range markets {
+		if strings.EqualFold(m.Outcome, outcome) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (q MarketQuery) cacheKey() string {
+	return strings.Join([]string{
+		q.Keyword, q.Category,
+		strconv.FormatFloat(q.MinVolume, 'f', -1, 64),
+		q.CloseBefore.Format(time.RFC3339), q.CloseAfter.Format(time.RFC3339),
+		strconv.FormatBool(q.ActiveOnly), q.Outcome,
+	}, "|")
+}
+
+// gammaMarket mirrors the subset of the Gamma /markets response we care
+// about. Gamma returns clobTokenIds/outcomes as JSON-encoded strings
+// rather than native arrays, so they're unmarshalled as raw strings and
+// decoded again in expandGammaMarkets.
+type gammaMarket struct {
+	ConditionID  string `json:"conditionId"`
+	Slug         string `json:"slug"`
+	Question     string `json:"question"`
+	Category     string `json:"category"`
+	Active       bool   `json:"active"`
+	NegRisk      bool   `json:"negRisk"`
+	Volume       string `json:"volume"`
+	EndDateISO   string `json:"endDate"`
+	Outcomes     string `json:"outcomes"`     // e.g. `["Yes","No"]`
+	ClobTokenIDs string `json:"clobTokenIds"` // e.g. `["123...","456..."]`
+}
+
+func (g gammaMarket) Volume64() float64 {
+	v, _ := strconv.ParseFloat(g.Volume, 64)
+	return v
+}
+
+// EndDate parses endDate as RFC3339, returning the zero time if it is
+// missing or malformed.
+func (g gammaMarket) EndDate() time.Time {
+	t, _ := time.Parse(time.RFC3339, g.EndDateISO)
+	return t
+}
+
+// expandGammaMarkets turns each Gamma market row into one Market per
+// outcome token, then enriches it with tick size from the CLOB /markets
+// endpoint (Gamma doesn't expose tick size).
+func (r *MarketResolver) expandGammaMarkets(raw []gammaMarket) ([]Market, error) {
+	var out []Market
+	for _, g := range raw {
+		var outcomes, tokenIDs []string
+		if err := json.Unmarshal([]byte(g.Outcomes), &outcomes); err != nil {
+			return nil, fmt.Errorf("decode outcomes for %q: %w", g.Slug, err)
+		}
+		if err := json.Unmarshal([]byte(g.ClobTokenIDs), &tokenIDs); err != nil {
+			return nil, fmt.Errorf("decode clobTokenIds for %q: %w", g.Slug, err)
+		}
+		if len(outcomes) != len(tokenIDs) {
+			return nil, fmt.Errorf("market %q: %d outcomes but %d token ids", g.Slug, len(outcomes), len(tokenIDs))
+		}
+
+		for i, tokenID := range tokenIDs {
+			tick, err := r.tickSize(tokenID)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, Market{
+				TokenID:     tokenID,
+				ConditionID: g.ConditionID,
+				Outcome:     outcomes[i],
+				Question:    g.Question,
+				Slug:        g.Slug,
+				Category:    g.Category,
+				TickSize:    tick,
+				NegRisk:     g.NegRisk,
+				Active:      g.Active,
+				Volume:      g.Volume64(),
+				CloseTime:   g.EndDate(),
+			})
+		}
+	}
+	return out, nil
+}
+
+func (r *MarketResolver) tickSize(tokenID string) (float64, error) {
+	var resp struct {
+		TickSize string `json:"tick_size"`
+	}
+	if err := r.getJSON(CLOBBaseURL+"/markets", url.Values{"token_id": {tokenID}}, &resp); err != nil {
+		return 0, fmt.Errorf("fetch tick size for %s: %w", tokenID, err)
+	}
+	tick, err := strconv.ParseFloat(resp.TickSize, 64)
+	if err != nil {
+		return 0.01, nil // CLOB default when absent/unparseable
+	}
+	return tick, nil
+}
+
+func (r *MarketResolver) getJSON(endpoint string, params url.Values, dst interface{}) error {
+	u := endpoint
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	resp, err := r.httpClient.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+func (r *MarketResolver) cacheGet(key string) ([]Market, bool) {
+	if r.cache == nil {
+		return nil, false
+	}
+	return r.cache.get(key)
+}
+
+func (r *MarketResolver) cacheSet(key string, markets []Market) {
+	if r.cache == nil {
+		return
+	}
+	r.cache.set(key, markets)
+}
+
+// marketCache persists resolver results to disk keyed by query, so
+// repeated invocations of the CLI don't re-hit Gamma/CLOB within the TTL.
+type marketCache struct {
+	dir string
+	ttl time.Duration
+}
+
+func newMarketCache(dir string, ttl time.Duration) *marketCache {
+	os.MkdirAll(dir, 0o755)
+	return &marketCache{dir: dir, ttl: ttl}
+}
+
+type cacheEntry struct {
+	StoredAt time.Time `json:"stored_at"`
+	Markets  []Market  `json:"markets"`
+}
+
+func (c *marketCache) path(key string) string {
+	safe := strings.NewReplacer("/", "_", ":", "_", " ", "_").Replace(key)
+	return filepath.Join(c.dir, safe+".json")
+}
+
+func (c *marketCache) get(key string) ([]Market, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if time.Since(entry.StoredAt) > c.ttl {
+		return nil, false
+	}
+	return entry.Markets, true
+}
+
+func (c *marketCache) set(key string, markets []Market) {
+	entry := cacheEntry{StoredAt: time.Now(), Markets: markets}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(c.path(key), data, 0o644)
+}
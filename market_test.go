@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestGammaMarketVolume64(t *testing.T) {
+	tests := []struct {
+		name string
+		in   gammaMarket
+		want float64
+	}{
+		{"parses decimal string", gammaMarket{Volume: "1234.56"}, 1234.56},
+		{"empty string is zero", gammaMarket{Volume: ""}, 0},
+		{"unparseable string is zero", gammaMarket{Volume: "not-a-number"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.in.Volume64(); got != tt.want {
+				t.Errorf("Volume64() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterOutcome(t *testing.T) {
+	markets := []Market{
+		{TokenID: "1", Outcome: "Yes"},
+		{TokenID: "2", Outcome: "No"},
+		{TokenID: "3", Outcome: "YES"},
+	}
+
+	got := filterOutcome(markets, "yes")
+	if len(got) != 2 {
+		t.Fatalf("filterOutcome(yes) = %d markets, want 2", len(got))
+	}
+	for _, m := range got {
+		if m.Outcome != "Yes" && m.Outcome != "YES" {
+			t.Errorf("unexpected outcome %q survived filter", m.Outcome)
+		}
+	}
+}
+
+func TestMarketQueryCacheKeyDiffersOnMinVolume(t *testing.T) {
+	a := MarketQuery{Category: "Politics", MinVolume: 100}
+	b := MarketQuery{Category: "Politics", MinVolume: 200}
+	if a.cacheKey() == b.cacheKey() {
+		t.Errorf("cacheKey() collided for queries with different MinVolume")
+	}
+}
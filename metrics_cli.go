@@ -0,0 +1,20 @@
+package main
+
+import "flag"
+
+// Flag controlling the built-in Prometheus/healthz HTTP server.
+var flagMetricsAddr = flag.String("metrics-addr", ":9090", "address to serve /metrics and /healthz on; empty disables the server")
+
+// StartMetricsServerFromFlags starts a MetricsServer in the background if
+// --metrics-addr is non-empty, returning it so callers can push shard
+// metrics via UpdateShardMetrics. Returns nil if metrics are disabled.
+func StartMetricsServerFromFlags(bus *EventBus) *MetricsServer {
+	if *flagMetricsAddr == "" {
+		return nil
+	}
+	m := NewMetricsServer(bus)
+	go func() {
+		_ = m.Run(*flagMetricsAddr)
+	}()
+	return m
+}
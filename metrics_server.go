@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsServer subscribes to an EventBus and exports per-asset
+// Prometheus gauges/counters/histograms on /metrics, plus a /healthz
+// probe that fails when any asset_id seen on the bus has had no event for
+// 30s, replacing the stdout-only observability the listener previously
+// had. /healthz does not look at shard state: the shard gauges set by
+// UpdateShardMetrics do not affect it.
+type MetricsServer struct {
+	bus      *EventBus
+	registry *prometheus.Registry
+
+	bestBid           *prometheus.GaugeVec
+	bestAsk           *prometheus.GaugeVec
+	spread            *prometheus.GaugeVec
+	mid               *prometheus.GaugeVec
+	bidDepth1pct      *prometheus.GaugeVec
+	askDepth1pct      *prometheus.GaugeVec
+	messagesTotal     *prometheus.CounterVec
+	reconnectsTotal   *prometheus.CounterVec
+	decodeErrorsTotal *prometheus.CounterVec
+	messageLatency    *prometheus.HistogramVec
+
+	shardAssetCount     *prometheus.GaugeVec
+	shardMessagesPerSec *prometheus.GaugeVec
+	shardLastEventAge   *prometheus.GaugeVec
+	shardReconnects     *prometheus.GaugeVec
+
+	mu          sync.RWMutex
+	lastEventAt map[string]time.Time // asset_id -> last event receive time, for /healthz
+}
+
+// NewMetricsServer builds a MetricsServer registered against its own
+// Prometheus registry (not the global default, so multiple instances
+// can coexist in tests).
+func NewMetricsServer(bus *EventBus) *MetricsServer {
+	labels := []string{"asset_id"}
+	m := &MetricsServer{
+		bus:         bus,
+		lastEventAt: make(map[string]time.Time),
+
+		bestBid: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "polymarket_best_bid", Help: "Current best bid price.",
+		}, labels),
+		bestAsk: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "polymarket_best_ask", Help: "Current best ask price.",
+		}, labels),
+		spread: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "polymarket_spread", Help: "Current best ask minus best bid.",
+		}, labels),
+		mid: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "polymarket_mid", Help: "Current best bid/ask midpoint.",
+		}, labels),
+		bidDepth1pct: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "polymarket_bid_depth_1pct", Help: "Bid size within 1% of best bid.",
+		}, labels),
+		askDepth1pct: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "polymarket_ask_depth_1pct", Help: "Ask size within 1% of best ask.",
+		}, labels),
+		messagesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "polymarket_messages_total", Help: "Total events received from the event bus, other than decode errors.",
+		}, labels),
+		reconnectsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "polymarket_reconnects_total", Help: "Total WS reconnects.",
+		}, labels),
+		decodeErrorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "polymarket_decode_errors_total", Help: "Total message decode errors.",
+		}, labels),
+		messageLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "polymarket_message_latency_seconds",
+			Help:    "Local receive time minus the message's server timestamp.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+	}
+
+	shardLabels := []string{"shard_index"}
+	m.shardAssetCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "polymarket_shard_asset_count", Help: "Number of asset_ids subscribed on this WS shard.",
+	}, shardLabels)
+	m.shardMessagesPerSec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "polymarket_shard_messages_per_second", Help: "Messages received on this WS shard per second, since the last sample.",
+	}, shardLabels)
+	m.shardLastEventAge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "polymarket_shard_last_event_age_seconds", Help: "Time since this WS shard last received a message.",
+	}, shardLabels)
+	m.shardReconnects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "polymarket_shard_reconnects_total", Help: "Total reconnects on this WS shard.",
+	}, shardLabels)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		m.bestBid, m.bestAsk, m.spread, m.mid,
+		m.bidDepth1pct, m.askDepth1pct,
+		m.messagesTotal, m.reconnectsTotal, m.decodeErrorsTotal,
+		m.messageLatency,
+		m.shardAssetCount, m.shardMessagesPerSec, m.shardLastEventAge, m.shardReconnects,
+	)
+	m.registry = registry
+	return m
+}
+
+// Run subscribes to the bus, updates metrics as events arrive, and
+// serves /metrics and /healthz on addr until the HTTP server returns an
+// error, dropping the bus subscription then. It blocks; call it in its own
+// goroutine.
+func (m *MetricsServer) Run(addr string) error {
+	events, unsubscribe := m.bus.Subscribe(1024)
+	defer unsubscribe()
+	go m.consume(events)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", m.handleHealthz)
+	return http.ListenAndServe(addr, mux)
+}
+
+func (m *MetricsServer) consume(events <-chan Event) {
+	for ev := range events {
+		if ev.Type == EventDecodeError {
+			m.decodeErrorsTotal.WithLabelValues(ev.AssetID).Inc()
+			continue
+		}
+
+		m.mu.Lock()
+		m.lastEventAt[ev.AssetID] = ev.Timestamp
+		m.mu.Unlock()
+
+		m.messagesTotal.WithLabelValues(ev.AssetID).Inc()
+		if !ev.ServerTime.IsZero() {
+			m.messageLatency.WithLabelValues(ev.AssetID).Observe(ev.Timestamp.Sub(ev.ServerTime).Seconds())
+		}
+
+		switch ev.Type {
+		case EventReconnect:
+			m.reconnectsTotal.WithLabelValues(ev.AssetID).Inc()
+		case EventBookSnapshot, EventBookDelta:
+			if view, ok := ev.Payload.(BookView); ok {
+				m.updateBookGauges(view)
+			}
+		}
+	}
+}
+
+func (m *MetricsServer) updateBookGauges(view BookView) {
+	bestBid, hasBid := firstLevel(view.Bids)
+	bestAsk, hasAsk := firstLevel(view.Asks)
+	if !hasBid || !hasAsk {
+		return
+	}
+
+	m.bestBid.WithLabelValues(view.AssetID).Set(bestBid.Price)
+	m.bestAsk.WithLabelValues(view.AssetID).Set(bestAsk.Price)
+	m.spread.WithLabelValues(view.AssetID).Set(bestAsk.Price - bestBid.Price)
+	m.mid.WithLabelValues(view.AssetID).Set((bestBid.Price + bestAsk.Price) / 2)
+	m.bidDepth1pct.WithLabelValues(view.AssetID).Set(depthWithinPct(view.Bids, bestBid.Price, 0.01, false))
+	m.askDepth1pct.WithLabelValues(view.AssetID).Set(depthWithinPct(view.Asks, bestAsk.Price, 0.01, true))
+}
+
+func firstLevel(levels []BookLevel) (BookLevel, bool) {
+	if len(levels) == 0 {
+		return BookLevel{}, false
+	}
+	return levels[0], true
+}
+
+// depthWithinPct sums size for levels within pct of best, where the
+// worse bound is best*(1+pct) for asks and best*(1-pct) for bids.
+func depthWithinPct(levels []BookLevel, best, pct float64, ascending bool) float64 {
+	var bound float64
+	if ascending {
+		bound = best * (1 + pct)
+	} else {
+		bound = best * (1 - pct)
+	}
+
+	var total float64
+	for _, lvl := range levels {
+		if ascending && lvl.Price > bound {
+			break
+		}
+		if !ascending && lvl.Price < bound {
+			break
+		}
+		total += lvl.Size
+	}
+	return total
+}
+
+// UpdateShardMetrics publishes a SubscriptionManager.Metrics() snapshot as
+// the polymarket_shard_* gauges, labeled by shard_index. Callers should
+// poll this periodically (see pollShardMetrics in main.go); it does not
+// subscribe to the bus itself since shard health isn't carried by Event.
+func (m *MetricsServer) UpdateShardMetrics(metrics []ShardMetrics) {
+	for _, sm := range metrics {
+		label := strconv.Itoa(sm.ShardIndex)
+		m.shardAssetCount.WithLabelValues(label).Set(float64(sm.AssetCount))
+		m.shardMessagesPerSec.WithLabelValues(label).Set(sm.MessagesPerSec)
+		m.shardLastEventAge.WithLabelValues(label).Set(sm.LastEventAge.Seconds())
+		m.shardReconnects.WithLabelValues(label).Set(float64(sm.ReconnectCount))
+	}
+}
+
+func (m *MetricsServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	const staleAfter = 30 * time.Second
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stale := make([]string, 0)
+	for assetID, last := range m.lastEventAt {
+		if time.Since(last) > staleAfter {
+			stale = append(stale, assetID)
+		}
+	}
+
+	if len(stale) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "stale assets: %v\n", stale)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// PriceStrategy identifies one of the supported ways of turning an L2
+// order book into a single price.
+type PriceStrategy string
+
+const (
+	// StrategyMidpoint is the plain best-bid/best-ask midpoint.
+	StrategyMidpoint PriceStrategy = "midpoint"
+	// StrategyNotionalVWAP walks the ask side until a target notional
+	// (in USDC) is filled and returns the volume-weighted average price,
+	// matching Polymarket's `/price?side=buy&size=N` semantics.
+	StrategyNotionalVWAP PriceStrategy = "notional_vwap"
+	// StrategySizeWeightedMid weights the bid/ask midpoints by top-of-book
+	// depth, reducing flicker on thin books.
+	StrategySizeWeightedMid PriceStrategy = "size_weighted_mid"
+)
+
+// PriceTick is a single derived price signal emitted for one asset_id.
+type PriceTick struct {
+	Timestamp time.Time
+	AssetID   string
+	Strategy  PriceStrategy
+	Price     float64
+	Spread    float64
+	DepthUsed float64
+}
+
+// BookLevel is one price/size pair on one side of an order book, the
+// common unit the aggregator strategies operate on.
+type BookLevel struct {
+	Price float64
+	Size  float64
+}
+
+// BookView is the minimal read-only view of an order book a pricing
+// strategy needs: best-to-worst sorted bid and ask levels.
+type BookView struct {
+	AssetID string
+	Bids    []BookLevel // best bid first (descending price)
+	Asks    []BookLevel // best ask first (ascending price)
+}
+
+func (b BookView) bestBid() (BookLevel, bool) {
+	if len(b.Bids) == 0 {
+		return BookLevel{}, false
+	}
+	return b.Bids[0], true
+}
+
+func (b BookView) bestAsk() (BookLevel, bool) {
+	if len(b.Asks) == 0 {
+		return BookLevel{}, false
+	}
+	return b.Asks[0], true
+}
+
+// AggregatorConfig selects which strategies a PriceAggregator computes
+// and, for the notional strategy, the target fill size.
+type AggregatorConfig struct {
+	Strategies     []PriceStrategy
+	NotionalTarget float64 // USDC size used by StrategyNotionalVWAP
+}
+
+// PriceAggregator consumes book updates and emits a PriceTick per
+// configured strategy on Ticks whenever a book is updated.
+type PriceAggregator struct {
+	cfg   AggregatorConfig
+	ticks chan PriceTick
+}
+
+// NewPriceAggregator builds a PriceAggregator. The returned aggregator's
+// Ticks channel must be drained by the caller or Update will block.
+func NewPriceAggregator(cfg AggregatorConfig) *PriceAggregator {
+	if cfg.NotionalTarget == 0 {
+		cfg.NotionalTarget = 100 // USDC, matches Polymarket's default /price size
+	}
+	return &PriceAggregator{
+		cfg:   cfg,
+		ticks: make(chan PriceTick, 256),
+	}
+}
+
+// Ticks returns the channel PriceTick values are published on.
+func (a *PriceAggregator) Ticks() <-chan PriceTick {
+	return a.ticks
+}
+
+// Close shuts down the aggregator's output channel. Callers must not call
+// Update after Close.
+func (a *PriceAggregator) Close() {
+	close(a.ticks)
+}
+
+// Update computes every configured strategy for the given book view and
+// publishes one PriceTick per strategy. It returns an error without
+// publishing anything if a requested strategy can't be computed (e.g. an
+// empty book).
+func (a *PriceAggregator) Update(book BookView, at time.Time) error {
+	bestBid, haveBid := book.bestBid()
+	bestAsk, haveAsk := book.bestAsk()
+	if !haveBid || !haveAsk {
+		return fmt.Errorf("price aggregator: asset %s has no two-sided book", book.AssetID)
+	}
+	spread := bestAsk.Price - bestBid.Price
+
+	ticks := make([]PriceTick, 0, len(a.cfg.Strategies))
+	for _, strat := range a.cfg.Strategies {
+		var tick PriceTick
+		switch strat {
+		case StrategyMidpoint:
+			tick = PriceTick{
+				Price:     (bestBid.Price + bestAsk.Price) / 2,
+				DepthUsed: 0,
+			}
+		case StrategyNotionalVWAP:
+			price, depth, err := notionalVWAP(book.Asks, a.cfg.NotionalTarget)
+			if err != nil {
+				return fmt.Errorf("price aggregator: asset %s: %w", book.AssetID, err)
+			}
+			tick = PriceTick{Price: price, DepthUsed: depth}
+		case StrategySizeWeightedMid:
+			tick = PriceTick{
+				Price:     sizeWeightedMid(bestBid, bestAsk),
+				DepthUsed: bestBid.Size + bestAsk.Size,
+			}
+		default:
+			return fmt.Errorf("price aggregator: unknown strategy %q", strat)
+		}
+
+		tick.Timestamp = at
+		tick.AssetID = book.AssetID
+		tick.Strategy = strat
+		tick.Spread = spread
+		ticks = append(ticks, tick)
+	}
+	for _, tick := range ticks {
+		a.ticks <- tick
+	}
+	return nil
+}
+
+// notionalVWAP walks the ask side accumulating size until notional USDC
+// has been filled, returning the volume-weighted average price and the
+// USDC depth actually consumed (which may be less than notional if the
+// book is too thin).
+func notionalVWAP(asks []BookLevel, notional float64) (price, depthUsed float64, err error) {
+	if len(asks) == 0 {
+		return 0, 0, fmt.Errorf("empty ask side")
+	}
+
+	var filledNotional, filledShares float64
+	for _, lvl := range asks {
+		levelNotional := lvl.Price * lvl.Size
+		remaining := notional - filledNotional
+		if levelNotional >= remaining {
+			shares := remaining / lvl.Price
+			filledShares += shares
+			filledNotional += remaining
+			break
+		}
+		filledShares += lvl.Size
+		filledNotional += levelNotional
+	}
+
+	if filledShares == 0 {
+		return 0, 0, fmt.Errorf("no liquidity available")
+	}
+	return filledNotional / filledShares, filledNotional, nil
+}
+
+// sizeWeightedMid weights the bid/ask midpoint by top-of-book depth,
+// pulling the result toward the price of the thinner side to reduce
+// flicker on thin books: price = bidPrice*askSize/(bidSize+askSize) + askPrice*bidSize/(bidSize+askSize).
+func sizeWeightedMid(bid, ask BookLevel) float64 {
+	totalSize := bid.Size + ask.Size
+	if totalSize == 0 {
+		return (bid.Price + ask.Price) / 2
+	}
+	return (bid.Price*ask.Size + ask.Price*bid.Size) / totalSize
+}
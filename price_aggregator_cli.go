@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Flags selecting which PriceAggregator strategies run against every book
+// update, giving users a price feed out of the binary instead of only the
+// library-level API.
+var (
+	flagPriceStrategies     = flag.String("price-strategies", "midpoint", "comma-separated price strategies to compute: midpoint, notional_vwap, size_weighted_mid")
+	flagPriceNotionalTarget = flag.Float64("price-notional-target", 100, "USDC size used by the notional_vwap strategy")
+)
+
+// PriceAggregatorFromFlags builds a PriceAggregator from
+// --price-strategies/--price-notional-target.
+func PriceAggregatorFromFlags() (*PriceAggregator, error) {
+	var strategies []PriceStrategy
+	for _, name := range strings.Split(*flagPriceStrategies, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		switch PriceStrategy(name) {
+		case StrategyMidpoint, StrategyNotionalVWAP, StrategySizeWeightedMid:
+			strategies = append(strategies, PriceStrategy(name))
+		default:
+			return nil, fmt.Errorf("unknown --price-strategies entry %q", name)
+		}
+	}
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("--price-strategies must name at least one strategy")
+	}
+
+	return NewPriceAggregator(AggregatorConfig{
+		Strategies:     strategies,
+		NotionalTarget: *flagPriceNotionalTarget,
+	}), nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestNotionalVWAPFillsAcrossLevels(t *testing.T) {
+	asks := []BookLevel{
+		{Price: 0.50, Size: 100}, // $50 notional
+		{Price: 0.55, Size: 100}, // $55 notional
+	}
+
+	price, depth, err := notionalVWAP(asks, 80)
+	if err != nil {
+		t.Fatalf("notionalVWAP() error = %v", err)
+	}
+	if depth != 80 {
+		t.Errorf("depthUsed = %v, want 80", depth)
+	}
+	// 50 @ 0.50 + 30 @ 0.55 = 80 notional over (100 + 54.545...) shares
+	wantShares := 100 + 30/0.55
+	wantPrice := 80 / wantShares
+	if diff := price - wantPrice; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("price = %v, want %v", price, wantPrice)
+	}
+}
+
+func TestNotionalVWAPPartialFillOnThinBook(t *testing.T) {
+	asks := []BookLevel{{Price: 1.0, Size: 10}} // only $10 of liquidity
+
+	price, depth, err := notionalVWAP(asks, 100)
+	if err != nil {
+		t.Fatalf("notionalVWAP() error = %v", err)
+	}
+	if depth != 10 {
+		t.Errorf("depthUsed = %v, want 10 (book too thin to fill target)", depth)
+	}
+	if price != 1.0 {
+		t.Errorf("price = %v, want 1.0", price)
+	}
+}
+
+func TestNotionalVWAPEmptyBook(t *testing.T) {
+	if _, _, err := notionalVWAP(nil, 100); err == nil {
+		t.Error("notionalVWAP(empty) error = nil, want error")
+	}
+}
+
+func TestSizeWeightedMidWeightsByOppositeSideSize(t *testing.T) {
+	bid := BookLevel{Price: 0.40, Size: 300}
+	ask := BookLevel{Price: 0.60, Size: 100}
+
+	// price = bidPrice*askSize/total + askPrice*bidSize/total, so a bid
+	// much larger than the ask pulls the result toward the ask price.
+	got := sizeWeightedMid(bid, ask)
+	want := (0.40*100 + 0.60*300) / 400
+	if got != want {
+		t.Errorf("sizeWeightedMid() = %v, want %v", got, want)
+	}
+}
+
+func TestSizeWeightedMidZeroSizeFallsBackToPlainMid(t *testing.T) {
+	bid := BookLevel{Price: 0.40, Size: 0}
+	ask := BookLevel{Price: 0.60, Size: 0}
+
+	if got := sizeWeightedMid(bid, ask); got != 0.50 {
+		t.Errorf("sizeWeightedMid() = %v, want 0.50", got)
+	}
+}
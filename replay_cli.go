@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Flags for replaying a previously captured history archive instead of
+// connecting to the live WS feed.
+var (
+	flagReplayPath  = flag.String("replay", "", "path to a SnapshotStore data directory to replay instead of going live")
+	flagReplaySpeed = flag.Float64("speed", 1, "replay speed multiplier, e.g. 10 for 10x realtime; <= 0 means as fast as possible")
+	flagReplayFrom  = flag.String("from", "", "only replay events at or after this RFC3339 timestamp")
+	flagReplayTo    = flag.String("to", "", "only replay events at or before this RFC3339 timestamp")
+)
+
+// ReplayerFromFlags builds a Replayer from --replay/--speed/--from/--to,
+// or returns nil if --replay wasn't set.
+func ReplayerFromFlags() (*Replayer, error) {
+	if *flagReplayPath == "" {
+		return nil, nil
+	}
+
+	opts := ReplayOptions{Speed: *flagReplaySpeed}
+	if *flagReplayFrom != "" {
+		from, err := time.Parse(time.RFC3339, *flagReplayFrom)
+		if err != nil {
+			return nil, fmt.Errorf("parse --from: %w", err)
+		}
+		opts.From = from
+	}
+	if *flagReplayTo != "" {
+		to, err := time.Parse(time.RFC3339, *flagReplayTo)
+		if err != nil {
+			return nil, fmt.Errorf("parse --to: %w", err)
+		}
+		opts.To = to
+	}
+
+	return NewReplayer(*flagReplayPath, opts), nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// ReplayOptions configures a Replayer run.
+type ReplayOptions struct {
+	From  time.Time // zero means "from the start"
+	To    time.Time // zero means "to the end"
+	Speed float64   // e.g. 10 for 10x realtime; 0 or negative means as-fast-as-possible
+}
+
+// Replayer reads back files written by a SnapshotStore and re-emits
+// BookEvents on Events, one partition file at a time with files ordered
+// by date and hour, so strategies and aggregators built against the live
+// channels can be developed and tested offline. Files of different assets
+// for the same hour are replayed one after another in full; their events
+// are not interleaved by timestamp.
+type Replayer struct {
+	dir    string
+	opts   ReplayOptions
+	Events chan BookEvent
+}
+
+// NewReplayer builds a Replayer over the data directory written by a
+// SnapshotStore (e.g. "data/"), which is expected to contain
+// {asset_id}/{yyyy-mm-dd}/hh.<ext> partitions.
+func NewReplayer(dir string, opts ReplayOptions) *Replayer {
+	return &Replayer{
+		dir:    dir,
+		opts:   opts,
+		Events: make(chan BookEvent, 256),
+	}
+}
+
+// Run reads every matching partition file under dir in partition order
+// and emits its events on Events, pacing them according to opts.Speed
+// (no pause is made where the next event's timestamp is not later).
+// It closes Events when done or when ctx-like cancellation isn't needed
+// because replay is bounded by the files on disk.
+func (r *Replayer) Run() error {
+	defer close(r.Events)
+
+	files, err := r.matchingFiles()
+	if err != nil {
+		return fmt.Errorf("replay %s: %w", r.dir, err)
+	}
+
+	var lastTS int64
+	first := true
+	for _, f := range files {
+		events, err := readPartition(f)
+		if err != nil {
+			return fmt.Errorf("replay %s: %w", f, err)
+		}
+		for _, ev := range events {
+			if !r.opts.From.IsZero() && ev.Timestamp < r.opts.From.UnixMilli() {
+				continue
+			}
+			if !r.opts.To.IsZero() && ev.Timestamp > r.opts.To.UnixMilli() {
+				continue
+			}
+
+			if r.opts.Speed > 0 && !first {
+				gap := time.Duration(ev.Timestamp-lastTS) * time.Millisecond
+				if gap > 0 {
+					time.Sleep(time.Duration(float64(gap) / r.opts.Speed))
+				}
+			}
+			lastTS = ev.Timestamp
+			first = false
+
+			r.Events <- ev
+		}
+	}
+	return nil
+}
+
+// matchingFiles walks dir and returns every partition file in timestamp
+// order, inferred from the {asset_id}/{yyyy-mm-dd}/hh.<ext> layout rather
+// than file mtimes so replays are reproducible regardless of when the
+// files were written to disk.
+func (r *Replayer) matchingFiles() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(r.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".jsonl", ".csv", ".parquet":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return partitionSortKey(files[i]) < partitionSortKey(files[j])
+	})
+	return files, nil
+}
+
+// partitionSortKey turns "data/{asset}/{yyyy-mm-dd}/{hh}.ext" into a
+// sortable "{yyyy-mm-dd}/{hh}" string so files sort by time first,
+// independent of asset_id.
+func partitionSortKey(path string) string {
+	hourFile := filepath.Base(path)
+	hour := strings.TrimSuffix(hourFile, filepath.Ext(hourFile))
+	date := filepath.Base(filepath.Dir(path))
+	return date + "/" + hour
+}
+
+func readPartition(path string) ([]BookEvent, error) {
+	switch filepath.Ext(path) {
+	case ".jsonl":
+		return readJSONLPartition(path)
+	case ".csv":
+		return readCSVPartition(path)
+	case ".parquet":
+		return readParquetPartition(path)
+	default:
+		return nil, fmt.Errorf("unsupported partition file %s", path)
+	}
+}
+
+func readJSONLPartition(path string) ([]BookEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []BookEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev BookEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("decode line: %w", err)
+		}
+		events = append(events, ev)
+	}
+	return events, scanner.Err()
+}
+
+func readCSVPartition(path string) ([]BookEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	events := make([]BookEvent, 0, len(rows)-1)
+	for _, fields := range rows[1:] { // skip header row
+		if len(fields) < 7 {
+			continue
+		}
+		ev, err := bookEventFromCSVRow(fields)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+func bookEventFromCSVRow(fields []string) (BookEvent, error) {
+	var ev BookEvent
+	ev.AssetID = fields[0]
+	ev.EventType = fields[1]
+	ts, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return ev, fmt.Errorf("parse timestamp: %w", err)
+	}
+	ev.Timestamp = ts
+	ev.Side = fields[3]
+	ev.Price, _ = strconv.ParseFloat(fields[4], 64)
+	ev.Size, _ = strconv.ParseFloat(fields[5], 64)
+	ev.Raw = fields[6]
+	return ev, nil
+}
+
+func readParquetPartition(path string) ([]BookEvent, error) {
+	fr, err := local.NewLocalFileReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fr.Close()
+
+	pr, err := reader.NewParquetReader(fr, new(BookEvent), 4)
+	if err != nil {
+		return nil, fmt.Errorf("open parquet reader: %w", err)
+	}
+	defer pr.ReadStop()
+
+	num := int(pr.GetNumRows())
+	events := make([]BookEvent, num)
+	if err := pr.Read(&events); err != nil {
+		return nil, fmt.Errorf("read parquet rows: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Flags for capturing the live book/price/trade stream to disk as a
+// history archive Replayer can read back. Off by default, since not
+// every run needs a durable archive.
+var (
+	flagCaptureDir      = flag.String("capture-dir", "", "write every book/price/trade message to this SnapshotStore directory; empty disables capture")
+	flagCaptureFormat   = flag.String("capture-format", "jsonl", "capture backend: jsonl, csv, or parquet")
+	flagCaptureMaxBytes = flag.Int64("capture-max-bytes", 0, "roll over a capture partition once it exceeds this size; 0 disables size-based rotation")
+)
+
+// SnapshotStoreFromFlags builds the SnapshotStore selected by
+// --capture-dir/--capture-format, or returns nil, nil if --capture-dir
+// wasn't set.
+func SnapshotStoreFromFlags() (SnapshotStore, error) {
+	if *flagCaptureDir == "" {
+		return nil, nil
+	}
+
+	policy := RotationPolicy{MaxBytes: *flagCaptureMaxBytes}
+	switch *flagCaptureFormat {
+	case "jsonl":
+		return NewJSONLStore(*flagCaptureDir, policy), nil
+	case "csv":
+		return NewCSVStore(*flagCaptureDir, policy), nil
+	case "parquet":
+		return NewParquetStore(*flagCaptureDir, policy), nil
+	default:
+		return nil, fmt.Errorf("unknown --capture-format %q: want jsonl, csv, or parquet", *flagCaptureFormat)
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// BookEvent is the unit of data persisted and replayed by SnapshotStore
+// and Replayer. It's a flattened view of whatever the live client
+// received (book snapshot, price_change, tick_size_change,
+// last_trade_price, or any other market-channel message) so every backend
+// can store it with one schema.
+type BookEvent struct {
+	AssetID   string  `json:"asset_id" parquet:"name=asset_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	EventType string  `json:"event_type" parquet:"name=event_type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Timestamp int64   `json:"timestamp" parquet:"name=timestamp, type=INT64"` // unix millis
+	Side      string  `json:"side,omitempty" parquet:"name=side, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Price     float64 `json:"price,omitempty" parquet:"name=price, type=DOUBLE"`
+	Size      float64 `json:"size,omitempty" parquet:"name=size, type=DOUBLE"`
+	Raw       string  `json:"raw,omitempty" parquet:"name=raw, type=BYTE_ARRAY, convertedtype=UTF8"` // original JSON payload
+}
+
+// SnapshotStore persists a stream of BookEvents to time-partitioned
+// files, rotating on size or hour boundaries. Implementations must be
+// safe for concurrent use by multiple asset streams.
+type SnapshotStore interface {
+	// Write appends one event, rotating the underlying file if needed.
+	Write(ev BookEvent) error
+	// Close flushes and closes any open file handles.
+	Close() error
+}
+
+// RotationPolicy controls when SnapshotStore backends roll over to a new
+// file, in addition to the mandatory hour boundary implied by the
+// {asset_id}/{yyyy-mm-dd}/hh partition layout.
+type RotationPolicy struct {
+	MaxBytes int64 // roll over once the current file exceeds this size; 0 disables
+}
+
+// partitionPath builds the data/{asset_id}/{yyyy-mm-dd}/hh.<ext> path for
+// an event at the given time, per the repo's history archive layout.
+func partitionPath(baseDir, assetID string, at time.Time, ext string) string {
+	return filepath.Join(baseDir, assetID, at.UTC().Format("2006-01-02"), fmt.Sprintf("%02d.%s", at.UTC().Hour(), ext))
+}
+
+// overflowPath builds the file a backend rotates to when a partition file
+// set by partitionPath(..., ext) already exceeds RotationPolicy.MaxBytes
+// within the same hour. The uniquifying suffix goes before ext rather than
+// after it (e.g. "00.<nanos>.csv", not "00.csv.<nanos>") so Replayer's
+// extension-based file discovery still finds it.
+func overflowPath(path, ext string) string {
+	base := strings.TrimSuffix(path, "."+ext)
+	return fmt.Sprintf("%s.%d.%s", base, time.Now().UnixNano(), ext)
+}
+
+// rotatingFile is shared state used by the JSONL and CSV backends to
+// decide when to open a new partition file.
+type rotatingFile struct {
+	mu         sync.Mutex
+	baseDir    string
+	ext        string
+	policy     RotationPolicy
+	openPath   string
+	openFile   *os.File
+	openHour   time.Time
+	bytesInUse int64
+}
+
+func newRotatingFile(baseDir, ext string, policy RotationPolicy) *rotatingFile {
+	return &rotatingFile{baseDir: baseDir, ext: ext, policy: policy}
+}
+
+// fileFor returns the *os.File to write ev into, opening a new partition
+// if the asset/hour has changed or the size threshold was exceeded.
+func (rf *rotatingFile) fileFor(assetID string, at time.Time) (*os.File, bool, error) {
+	path := partitionPath(rf.baseDir, assetID, at, rf.ext)
+	hour := at.UTC().Truncate(time.Hour)
+
+	needsRotate := rf.openFile == nil || path != rf.openPath || !hour.Equal(rf.openHour)
+	if !needsRotate && rf.policy.MaxBytes > 0 && rf.bytesInUse >= rf.policy.MaxBytes {
+		needsRotate = true
+	}
+	if !needsRotate {
+		return rf.openFile, false, nil
+	}
+
+	if rf.openFile != nil {
+		rf.openFile.Close()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, false, fmt.Errorf("create partition dir: %w", err)
+	}
+
+	// MaxBytes rotation within the same hour needs a unique suffix so we
+	// don't clobber the file we just rotated away from.
+	finalPath := path
+	if rf.policy.MaxBytes > 0 {
+		if info, err := os.Stat(path); err == nil && info.Size() >= rf.policy.MaxBytes {
+			finalPath = overflowPath(path, rf.ext)
+		}
+	}
+
+	f, err := os.OpenFile(finalPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, false, fmt.Errorf("open partition file %s: %w", finalPath, err)
+	}
+	info, _ := f.Stat()
+	rf.openFile = f
+	rf.openPath = path
+	rf.openHour = hour
+	rf.bytesInUse = 0
+	if info != nil {
+		rf.bytesInUse = info.Size()
+	}
+	return f, true, nil
+}
+
+// JSONLStore writes one JSON-encoded BookEvent per line.
+type JSONLStore struct {
+	rf *rotatingFile
+}
+
+// NewJSONLStore creates a JSONLStore rooted at baseDir.
+func NewJSONLStore(baseDir string, policy RotationPolicy) *JSONLStore {
+	return &JSONLStore{rf: newRotatingFile(baseDir, "jsonl", policy)}
+}
+
+func (s *JSONLStore) Write(ev BookEvent) error {
+	s.rf.mu.Lock()
+	defer s.rf.mu.Unlock()
+
+	at := time.UnixMilli(ev.Timestamp)
+	f, _, err := s.rf.fileFor(ev.AssetID, at)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	n, err := f.Write(append(data, '\n'))
+	s.rf.bytesInUse += int64(n)
+	return err
+}
+
+func (s *JSONLStore) Close() error {
+	s.rf.mu.Lock()
+	defer s.rf.mu.Unlock()
+	if s.rf.openFile != nil {
+		return s.rf.openFile.Close()
+	}
+	return nil
+}
+
+var csvHeader = []string{"asset_id", "event_type", "timestamp", "side", "price", "size", "raw"}
+
+// countingWriter tracks bytes written through it, so CSVStore can feed
+// rf.bytesInUse the same way JSONLStore does from its f.Write return value.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// csvWriterState pairs a csv.Writer with the counter measuring the bytes
+// it has flushed to its underlying file.
+type csvWriterState struct {
+	w   *csv.Writer
+	cnt *countingWriter
+}
+
+// CSVStore writes one CSV row per event, with a header row on new files.
+type CSVStore struct {
+	rf      *rotatingFile
+	writers map[string]*csvWriterState
+}
+
+// NewCSVStore creates a CSVStore rooted at baseDir.
+func NewCSVStore(baseDir string, policy RotationPolicy) *CSVStore {
+	return &CSVStore{
+		rf:      newRotatingFile(baseDir, "csv", policy),
+		writers: make(map[string]*csvWriterState),
+	}
+}
+
+func (s *CSVStore) Write(ev BookEvent) error {
+	s.rf.mu.Lock()
+	defer s.rf.mu.Unlock()
+
+	at := time.UnixMilli(ev.Timestamp)
+	f, isNew, err := s.rf.fileFor(ev.AssetID, at)
+	if err != nil {
+		return err
+	}
+
+	state, ok := s.writers[s.rf.openPath]
+	if !ok || isNew {
+		cnt := &countingWriter{w: f}
+		state = &csvWriterState{w: csv.NewWriter(cnt), cnt: cnt}
+		s.writers[s.rf.openPath] = state
+		// A reopened partition that already has rows keeps its header.
+		if isNew && s.rf.bytesInUse == 0 {
+			if err := state.w.Write(csvHeader); err != nil {
+				return fmt.Errorf("write csv header: %w", err)
+			}
+			state.w.Flush()
+			s.rf.bytesInUse += state.cnt.n
+			state.cnt.n = 0
+		}
+	}
+
+	row := []string{
+		ev.AssetID, ev.EventType, strconv.FormatInt(ev.Timestamp, 10),
+		ev.Side, strconv.FormatFloat(ev.Price, 'f', -1, 64), strconv.FormatFloat(ev.Size, 'f', -1, 64),
+		ev.Raw,
+	}
+	if err := state.w.Write(row); err != nil {
+		return fmt.Errorf("write csv row: %w", err)
+	}
+	state.w.Flush()
+	if err := state.w.Error(); err != nil {
+		return err
+	}
+	s.rf.bytesInUse += state.cnt.n
+	state.cnt.n = 0
+	return nil
+}
+
+func (s *CSVStore) Close() error {
+	s.rf.mu.Lock()
+	defer s.rf.mu.Unlock()
+	if s.rf.openFile != nil {
+		return s.rf.openFile.Close()
+	}
+	return nil
+}
+
+// ParquetStore writes BookEvents to time-partitioned Parquet files using
+// the repo's BookEvent struct tags as the schema.
+type ParquetStore struct {
+	mu sync.Mutex
+
+	baseDir string
+	policy  RotationPolicy
+
+	openPath   string // canonical partitionPath(...), used to detect a new partition
+	activeFile string // actual open file, which may carry an overflowPath suffix
+	openHour   time.Time
+	fw         source.ParquetFile
+	pw         *writer.ParquetWriter
+	rows       int64
+}
+
+// NewParquetStore creates a ParquetStore rooted at baseDir.
+func NewParquetStore(baseDir string, policy RotationPolicy) *ParquetStore {
+	return &ParquetStore{baseDir: baseDir, policy: policy}
+}
+
+func (s *ParquetStore) Write(ev BookEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at := time.UnixMilli(ev.Timestamp)
+	path := partitionPath(s.baseDir, ev.AssetID, at, "parquet")
+	hour := at.UTC().Truncate(time.Hour)
+
+	needsRotate := s.pw == nil || path != s.openPath || !hour.Equal(s.openHour)
+	if !needsRotate && s.policy.MaxBytes > 0 && s.rows > 0 {
+		if info, err := os.Stat(s.activeFile); err == nil && info.Size() >= s.policy.MaxBytes {
+			needsRotate = true
+		}
+	}
+
+	if needsRotate {
+		if err := s.rotate(path, hour); err != nil {
+			return err
+		}
+	}
+
+	if err := s.pw.Write(ev); err != nil {
+		return fmt.Errorf("write parquet row: %w", err)
+	}
+	s.rows++
+	return nil
+}
+
+func (s *ParquetStore) rotate(path string, hour time.Time) error {
+	if s.pw != nil {
+		if err := s.pw.WriteStop(); err != nil {
+			return fmt.Errorf("flush parquet file: %w", err)
+		}
+		s.fw.Close()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create partition dir: %w", err)
+	}
+	finalPath := path
+	if _, err := os.Stat(path); err == nil {
+		finalPath = overflowPath(path, "parquet")
+	}
+
+	fw, err := local.NewLocalFileWriter(finalPath)
+	if err != nil {
+		return fmt.Errorf("open parquet file %s: %w", finalPath, err)
+	}
+	pw, err := writer.NewParquetWriter(fw, new(BookEvent), 4)
+	if err != nil {
+		fw.Close()
+		return fmt.Errorf("create parquet writer: %w", err)
+	}
+
+	s.fw = fw
+	s.pw = pw
+	s.openPath = path
+	s.activeFile = finalPath
+	s.openHour = hour
+	s.rows = 0
+	return nil
+}
+
+func (s *ParquetStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pw == nil {
+		return nil
+	}
+	if err := s.pw.WriteStop(); err != nil {
+		s.fw.Close()
+		return fmt.Errorf("flush parquet file: %w", err)
+	}
+	return s.fw.Close()
+}
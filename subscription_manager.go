@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// PolymarketWSURL is the CLOB market-channel WebSocket endpoint.
+var PolymarketWSURL = "wss://ws-subscriptions-clob.polymarket.com/ws/market"
+
+// maxAssetsPerShard matches Polymarket's documented cap on the number of
+// asset_ids a single market-channel WS connection may subscribe to.
+const maxAssetsPerShard = 500
+
+// ShardMetrics reports the health of a single WS shard.
+type ShardMetrics struct {
+	ShardIndex     int
+	AssetCount     int
+	MessagesPerSec float64
+	LastEventAge   time.Duration
+	ReconnectCount int
+}
+
+// SubscriptionManager shards asset IDs across multiple WebSocket
+// connections (Polymarket caps subscriptions per socket), reconnects
+// each shard independently with exponential backoff, and resyncs via a
+// REST snapshot after every reconnect so missed diff events don't leave
+// a shard's books stale. Callers add/remove markets at runtime via
+// Subscribe/Unsubscribe instead of restarting the process.
+type SubscriptionManager struct {
+	mu          sync.Mutex
+	shards      []*wsShard
+	onMessage   func(assetID string, raw []byte)
+	onReconnect func(assetID string, snapshot []byte)
+	bus         *EventBus
+
+	httpClient *http.Client
+}
+
+// NewSubscriptionManager builds a manager with no shards yet. onMessage
+// is invoked for every raw message received on any shard; onReconnect is
+// invoked with a fresh REST /book snapshot for each asset_id on a shard
+// after it reconnects, so callers can reconcile local state. bus may be
+// nil, in which case the manager runs without publishing reconnect/decode
+// events (the onMessage/onReconnect callbacks still fire as before).
+func NewSubscriptionManager(onMessage func(assetID string, raw []byte), onReconnect func(assetID string, snapshot []byte), bus *EventBus) *SubscriptionManager {
+	return &SubscriptionManager{
+		onMessage:   onMessage,
+		onReconnect: onReconnect,
+		bus:         bus,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Subscribe adds assetID to monitoring, placing it on an existing shard
+// with spare capacity or opening a new shard if all are full.
+func (m *SubscriptionManager) Subscribe(assetID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.shards {
+		if s.has(assetID) {
+			return nil // already subscribed
+		}
+	}
+
+	shard := m.shardWithSpareCapacity()
+	if shard == nil {
+		shard = m.newShard()
+	}
+	return shard.subscribe(assetID)
+}
+
+// Unsubscribe removes assetID from whichever shard currently holds it.
+func (m *SubscriptionManager) Unsubscribe(assetID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.shards {
+		if s.has(assetID) {
+			return s.unsubscribe(assetID)
+		}
+	}
+	return fmt.Errorf("subscription manager: %s is not subscribed", assetID)
+}
+
+// Metrics returns a snapshot of per-shard health.
+func (m *SubscriptionManager) Metrics() []ShardMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]ShardMetrics, len(m.shards))
+	for i, s := range m.shards {
+		out[i] = s.metrics(i)
+	}
+	return out
+}
+
+// Close tears down every shard's connection.
+func (m *SubscriptionManager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var firstErr error
+	for _, s := range m.shards {
+		if err := s.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *SubscriptionManager) shardWithSpareCapacity() *wsShard {
+	for _, s := range m.shards {
+		if s.assetCount() < maxAssetsPerShard {
+			return s
+		}
+	}
+	return nil
+}
+
+func (m *SubscriptionManager) newShard() *wsShard {
+	s := newWSShard(len(m.shards), m.onMessage, m.onReconnect, m.httpClient, m.bus)
+	m.shards = append(m.shards, s)
+	s.run()
+	return s
+}
+
+// wsShard owns one WebSocket connection and the asset_ids subscribed on
+// it. It reconnects itself with exponential backoff and resubscribes to
+// all of its assets on reconnect.
+type wsShard struct {
+	mu         sync.Mutex
+	index      int
+	conn       *websocket.Conn
+	assets     map[string]bool
+	messages   int
+	lastMsg    time.Time
+	reconnects int
+	closed     bool
+
+	// prevMessages/prevSampleAt let metrics compute MessagesPerSec as a
+	// rate between successive calls, rather than a cumulative count.
+	prevMessages int
+	prevSampleAt time.Time
+
+	onMessage   func(assetID string, raw []byte)
+	onReconnect func(assetID string, snapshot []byte)
+	httpClient  *http.Client
+	bus         *EventBus
+}
+
+func newWSShard(index int, onMessage func(string, []byte), onReconnect func(string, []byte), httpClient *http.Client, bus *EventBus) *wsShard {
+	return &wsShard{
+		index:       index,
+		assets:      make(map[string]bool),
+		onMessage:   onMessage,
+		onReconnect: onReconnect,
+		httpClient:  httpClient,
+		bus:         bus,
+	}
+}
+
+func (s *wsShard) has(assetID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.assets[assetID]
+}
+
+func (s *wsShard) assetCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.assets)
+}
+
+func (s *wsShard) subscribe(assetID string) error {
+	s.mu.Lock()
+	s.assets[assetID] = true
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil // picked up by the next (re)connect
+	}
+	return s.sendSubscribe(conn, []string{assetID})
+}
+
+func (s *wsShard) unsubscribe(assetID string) error {
+	s.mu.Lock()
+	delete(s.assets, assetID)
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.WriteJSON(map[string]interface{}{
+		"type":       "unsubscribe",
+		"assets_ids": []string{assetID},
+	})
+}
+
+func (s *wsShard) sendSubscribe(conn *websocket.Conn, assetIDs []string) error {
+	return conn.WriteJSON(map[string]interface{}{
+		"type":       "subscribe",
+		"assets_ids": assetIDs,
+	})
+}
+
+// run connects the shard and keeps it connected in a background
+// goroutine, reconnecting with exponential backoff on any error.
+func (s *wsShard) run() {
+	go func() {
+		backoff := time.Second
+		const maxBackoff = 30 * time.Second
+		for {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return
+			}
+
+			if err := s.connectAndRead(); err != nil {
+				s.mu.Lock()
+				s.reconnects++
+				s.mu.Unlock()
+				time.Sleep(backoff)
+				backoff *= 2
+				if backoff > maxBackoff {
+					backoff = maxBackoff
+				}
+				continue
+			}
+			backoff = time.Second
+		}
+	}()
+}
+
+func (s *wsShard) connectAndRead() error {
+	conn, _, err := websocket.DefaultDialer.Dial(PolymarketWSURL, nil)
+	if err != nil {
+		return fmt.Errorf("shard %d: dial: %w", s.index, err)
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.conn = conn
+	assetIDs := make([]string, 0, len(s.assets))
+	for id := range s.assets {
+		assetIDs = append(assetIDs, id)
+	}
+	s.mu.Unlock()
+
+	if len(assetIDs) > 0 {
+		if err := s.sendSubscribe(conn, assetIDs); err != nil {
+			return fmt.Errorf("shard %d: resubscribe: %w", s.index, err)
+		}
+		s.reconcile(assetIDs)
+	}
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			s.mu.Lock()
+			s.conn = nil
+			s.mu.Unlock()
+			return fmt.Errorf("shard %d: read: %w", s.index, err)
+		}
+
+		s.mu.Lock()
+		s.messages++
+		s.lastMsg = time.Now()
+		s.mu.Unlock()
+
+		assetID, ok := extractAssetID(msg)
+		if !ok {
+			s.publish(Event{Type: EventDecodeError})
+			continue
+		}
+		if s.onMessage != nil {
+			s.onMessage(assetID, msg)
+		}
+	}
+}
+
+// reconcile fetches a fresh REST snapshot for each asset_id so any diff
+// events missed while disconnected don't leave the local book stale.
+func (s *wsShard) reconcile(assetIDs []string) {
+	for _, assetID := range assetIDs {
+		resp, err := s.httpClient.Get(CLOBBaseURL + "/book?token_id=" + assetID)
+		if err != nil {
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+		if s.onReconnect != nil {
+			s.onReconnect(assetID, body)
+		}
+		s.publish(Event{Type: EventReconnect, AssetID: assetID})
+	}
+}
+
+// publish is a no-op when no bus was configured.
+func (s *wsShard) publish(ev Event) {
+	if s.bus == nil {
+		return
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now()
+	}
+	s.bus.Publish(ev)
+}
+
+// metrics returns a ShardMetrics snapshot. MessagesPerSec is the message
+// rate since the previous call to metrics; it's 0 on the first call (no
+// prior sample to measure against).
+func (s *wsShard) metrics(index int) ShardMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var rate float64
+	if !s.prevSampleAt.IsZero() {
+		if elapsed := now.Sub(s.prevSampleAt).Seconds(); elapsed > 0 {
+			rate = float64(s.messages-s.prevMessages) / elapsed
+		}
+	}
+	s.prevMessages = s.messages
+	s.prevSampleAt = now
+
+	return ShardMetrics{
+		ShardIndex:     index,
+		AssetCount:     len(s.assets),
+		MessagesPerSec: rate,
+		LastEventAge:   time.Since(s.lastMsg),
+		ReconnectCount: s.reconnects,
+	}
+}
+
+func (s *wsShard) close() error {
+	s.mu.Lock()
+	s.closed = true
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// extractAssetID pulls the asset_id field out of a raw market-channel
+// message without fully decoding its (message-type-dependent) payload.
+// ok is false if raw isn't valid JSON.
+func extractAssetID(raw []byte) (assetID string, ok bool) {
+	var envelope struct {
+		AssetID string `json:"asset_id"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return "", false
+	}
+	return envelope.AssetID, true
+}
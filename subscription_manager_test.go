@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractAssetID(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		want   string
+		wantOK bool
+	}{
+		{"valid message", `{"asset_id":"123","event_type":"book"}`, "123", true},
+		{"missing field defaults to empty", `{"event_type":"book"}`, "", true},
+		{"invalid json", `not json`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := extractAssetID([]byte(tt.raw))
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("extractAssetID(%q) = (%q, %v), want (%q, %v)", tt.raw, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWSShardSubscribeTracksAssets(t *testing.T) {
+	s := newWSShard(0, nil, nil, nil, nil)
+
+	if err := s.subscribe("asset-1"); err != nil {
+		t.Fatalf("subscribe() error = %v", err)
+	}
+	if !s.has("asset-1") {
+		t.Error("has(asset-1) = false after subscribe")
+	}
+	if got := s.assetCount(); got != 1 {
+		t.Errorf("assetCount() = %d, want 1", got)
+	}
+
+	if err := s.unsubscribe("asset-1"); err != nil {
+		t.Fatalf("unsubscribe() error = %v", err)
+	}
+	if s.has("asset-1") {
+		t.Error("has(asset-1) = true after unsubscribe")
+	}
+}
+
+func TestSubscriptionManagerShardWithSpareCapacity(t *testing.T) {
+	full := newWSShard(0, nil, nil, nil, nil)
+	for i := 0; i < maxAssetsPerShard; i++ {
+		full.assets[assetName(i)] = true
+	}
+
+	m := &SubscriptionManager{shards: []*wsShard{full}}
+	if got := m.shardWithSpareCapacity(); got != nil {
+		t.Error("shardWithSpareCapacity() = non-nil shard, want nil when every shard is full")
+	}
+
+	spare := newWSShard(1, nil, nil, nil, nil)
+	spare.assets["asset-0"] = true
+	m.shards = append(m.shards, spare)
+	if got := m.shardWithSpareCapacity(); got != spare {
+		t.Error("shardWithSpareCapacity() did not return the shard with room")
+	}
+}
+
+func TestWSShardMetricsComputesRateBetweenSamples(t *testing.T) {
+	s := newWSShard(2, nil, nil, nil, nil)
+
+	first := s.metrics(2)
+	if first.MessagesPerSec != 0 {
+		t.Errorf("first sample MessagesPerSec = %v, want 0 (no prior sample)", first.MessagesPerSec)
+	}
+
+	s.mu.Lock()
+	s.messages = 20
+	s.prevSampleAt = time.Now().Add(-2 * time.Second) // fake elapsed time instead of sleeping
+	s.mu.Unlock()
+
+	second := s.metrics(2)
+	if got, want := second.MessagesPerSec, 10.0; got < want-0.5 || got > want+0.5 {
+		t.Errorf("second sample MessagesPerSec = %v, want ~%v (20 messages over ~2s)", got, want)
+	}
+}
+
+func assetName(i int) string {
+	return "asset-" + string(rune('a'+i%26)) + string(rune('0'+i/26))
+}